@@ -2,33 +2,76 @@ package poker
 
 import (
 	"bufio"
+	"context"
+	"fmt"
 	"io"
-	"strings"
 )
 
 type CLI struct {
 	playerStore PlayerStore
 	in          *bufio.Scanner
+	out         io.Writer
 }
 
-func NewCLI(store PlayerStore, in io.Reader) *CLI {
-	return &CLI{
+// CLIOption configures a CLI.
+type CLIOption func(*CLI)
+
+// WithCLIOutput prints the answers to score and league commands to out.
+func WithCLIOutput(out io.Writer) CLIOption {
+	return func(c *CLI) {
+		c.out = out
+	}
+}
+
+func NewCLI(store PlayerStore, in io.Reader, options ...CLIOption) *CLI {
+	cli := &CLI{
 		playerStore: store,
 		in:          bufio.NewScanner(in),
+		out:         io.Discard,
 	}
+
+	for _, option := range options {
+		option(cli)
+	}
+
+	return cli
 }
 
-func (c *CLI) PlayPoker() {
-	userInput := c.readLine()
+// PlayPoker reads a command and carries it out.
+func (c *CLI) PlayPoker() error {
+	command, err := ParseCommand(c.readLine())
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch command.Action {
+	case CommandScore:
+		score, err := c.playerStore.GetPlayerScore(ctx, command.Player)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(c.out, formatScore(command.Player, score))
+		return nil
+	case CommandLeague:
+		league, err := c.playerStore.GetLeague(ctx)
+
+		if err != nil {
+			return err
+		}
 
-	c.playerStore.RecordWin(extractWinner(userInput))
+		fmt.Fprintln(c.out, formatLeague(league))
+		return nil
+	}
+
+	return c.playerStore.RecordWin(ctx, command.Player)
 }
 
 func (c *CLI) readLine() string {
 	c.in.Scan()
 	return c.in.Text()
 }
-
-func extractWinner(userInput string) string {
-	return strings.Replace(userInput, " wins", "", 1)
-}
@@ -1,7 +1,11 @@
 package poker_test
 
 import (
+	"bytes"
 	poker "command-line-and-project-structure"
+	"context"
+	"errors"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -26,4 +30,176 @@ func TestCLI(t *testing.T) {
 
 		poker.AssertPlayerWin(t, playerStore, "Cleo")
 	})
+
+	t.Run("audit the win on behalf of the user", func(t *testing.T) {
+		in := strings.NewReader("Chris wins\n")
+
+		auditLog, closeAudit, err := poker.AuditLogFromFile(filepath.Join(t.TempDir(), "audit.jsonl"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeAudit()
+
+		origin := poker.Origin{Actor: "ruth", Source: poker.AuditSourceCLI}
+		playerStore, closeStore, err := poker.FileSystemFileStoreFromFile(filepath.Join(t.TempDir(), "game.db.json"), poker.WithStoreAuditLog(auditLog, origin))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeStore()
+
+		cli := poker.NewCLI(playerStore, in)
+		if err := cli.PlayPoker(); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, _ := auditLog.Entries("Chris")
+
+		if len(entries) != 1 || entries[0].Actor != "ruth" || entries[0].Source != poker.AuditSourceCLI {
+			t.Errorf("unexpected audit entries %+v", entries)
+		}
+	})
+
+	t.Run("prints a player's score", func(t *testing.T) {
+		in := strings.NewReader("score Chris\n")
+		out := &bytes.Buffer{}
+		playerStore := &poker.StubPlayerStore{}
+		playerStore.RecordWin(context.Background(), "Chris")
+
+		cli := poker.NewCLI(playerStore, in, poker.WithCLIOutput(out))
+		if err := cli.PlayPoker(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := out.String(), "Chris has won 1 game\n"; got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+
+	t.Run("prints the league", func(t *testing.T) {
+		in := strings.NewReader("league\n")
+		out := &bytes.Buffer{}
+		playerStore := &poker.StubPlayerStore{}
+		playerStore.RecordWin(context.Background(), "Chris")
+
+		cli := poker.NewCLI(playerStore, in, poker.WithCLIOutput(out))
+		if err := cli.PlayPoker(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got, want := out.String(), "1. Chris 1\n"; got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+
+	t.Run("rejects an unknown command", func(t *testing.T) {
+		in := strings.NewReader("Chris\n")
+		playerStore := &poker.StubPlayerStore{}
+
+		cli := poker.NewCLI(playerStore, in)
+
+		if err := cli.PlayPoker(); !errors.Is(err, poker.ErrUnknownCommand) {
+			t.Errorf("got error %v want %v", err, poker.ErrUnknownCommand)
+		}
+	})
+}
+
+func TestCLIBatch(t *testing.T) {
+	newStore := func(t *testing.T) *poker.FileSystemPlayerStore {
+		store, close, err := poker.FileSystemFileStoreFromFile(filepath.Join(t.TempDir(), "game.db.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(close)
+
+		if err := store.RecordWin(context.Background(), "Cleo"); err != nil {
+			t.Fatal(err)
+		}
+
+		return store
+	}
+
+	batch := "# friday night\nChris wins\n\nCleo wins\nChris wins\n"
+
+	t.Run("records every win and reports the league", func(t *testing.T) {
+		store := newStore(t)
+		out := &bytes.Buffer{}
+
+		cli := poker.NewCLI(store, strings.NewReader(batch), poker.WithCLIOutput(out))
+		if err := cli.PlayBatch(false); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "Recorded 3 wins for 2 players\n  Chris +2\n  Cleo +1\n\n1. Chris 2\n2. Cleo 2\n"
+		if got := out.String(); got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+
+		assertCLIScore(t, store, "Chris", 2)
+	})
+
+	t.Run("shows the league without recording anything in a dry run", func(t *testing.T) {
+		store := newStore(t)
+		out := &bytes.Buffer{}
+
+		cli := poker.NewCLI(store, strings.NewReader(batch), poker.WithCLIOutput(out))
+		if err := cli.PlayBatch(true); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := out.String(); !strings.HasPrefix(got, "Would record 3 wins for 2 players\n") || !strings.HasSuffix(got, "1. Chris 2\n2. Cleo 2\n") {
+			t.Errorf("unexpected dry run report %q", got)
+		}
+
+		assertCLIScore(t, store, "Chris", 0)
+	})
+
+	t.Run("reports every bad line and records nothing", func(t *testing.T) {
+		playerStore := &poker.StubPlayerStore{}
+
+		cli := poker.NewCLI(playerStore, strings.NewReader("Chris wins\nChris\nCleo wins\nleague\n"))
+		err := cli.PlayBatch(false)
+
+		var batchErr poker.BatchError
+		if !errors.As(err, &batchErr) {
+			t.Fatalf("got error %v want a BatchError", err)
+		}
+
+		if len(batchErr) != 2 || batchErr[0].Line != 2 || batchErr[1].Line != 4 {
+			t.Errorf("unexpected bad lines %+v", batchErr)
+		}
+
+		if !errors.Is(batchErr[0], poker.ErrUnknownCommand) {
+			t.Errorf("got error %v want %v", batchErr[0], poker.ErrUnknownCommand)
+		}
+
+		if score, _ := playerStore.GetPlayerScore(context.Background(), "Chris"); score != 0 {
+			t.Errorf("recorded %d wins for Chris from a bad batch", score)
+		}
+	})
+	t.Run("refuses stores that cannot record a batch all at once", func(t *testing.T) {
+		playerStore := &poker.StubPlayerStore{}
+
+		cli := poker.NewCLI(playerStore, strings.NewReader(batch))
+
+		if err := cli.PlayBatch(false); !errors.Is(err, poker.ErrBatchUnsupported) {
+			t.Errorf("got error %v want %v", err, poker.ErrBatchUnsupported)
+		}
+
+		if score, _ := playerStore.GetPlayerScore(context.Background(), "Chris"); score != 0 {
+			t.Errorf("recorded %d wins for Chris from a refused batch", score)
+		}
+	})
+}
+
+func assertCLIScore(t testing.TB, store poker.PlayerStore, name string, want int) {
+	t.Helper()
+
+	got, err := store.GetPlayerScore(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != want {
+		t.Errorf("got %d wins for %s want %d", got, name, want)
+	}
 }
@@ -0,0 +1,396 @@
+package poker
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sources of league mutations recorded in the audit log.
+const (
+	AuditSourceHTTP = "HTTP"
+	AuditSourceCLI  = "CLI"
+)
+
+// Actions recorded in the audit log, one for each kind of change made to the league or ledger.
+const (
+	AuditActionWin         = "win"
+	AuditActionImport      = "import"
+	AuditActionRekey       = "rekey"
+	AuditActionSeason      = "new-season"
+	AuditActionRestore     = "restore"
+	AuditActionTransaction = "transaction"
+)
+
+// AuditEntry describes a single change made to the league. Actor is who the change is known to
+// come from, such as the user running the CLI or the token or address of a HTTP client.
+// ClaimedActor is a name the client gave for itself that nothing has checked.
+type AuditEntry struct {
+	Time         time.Time
+	Action       string
+	Player       string
+	Detail       string `json:",omitempty"`
+	Actor        string
+	ClaimedActor string `json:",omitempty"`
+	Source       string
+	RequestID    string
+}
+
+// Origin says who is changing the league, through what, for the audit log. Stores that keep an
+// audit log take it from the context of each change.
+type Origin struct {
+	Actor        string
+	ClaimedActor string
+	Source       string
+	RequestID    string
+}
+
+type originKey struct{}
+
+// ContextWithOrigin returns a copy of ctx carrying origin.
+func ContextWithOrigin(ctx context.Context, origin Origin) context.Context {
+	return context.WithValue(ctx, originKey{}, origin)
+}
+
+// OriginFromContext returns the origin carried by ctx, if it carries one.
+func OriginFromContext(ctx context.Context) (Origin, bool) {
+	origin, ok := ctx.Value(originKey{}).(Origin)
+	return origin, ok
+}
+
+// AuditLog is an append-only log of league mutations stored as JSON lines. If it has a key each
+// line is encrypted, so the log gives away no more than the database it audits.
+type AuditLog struct {
+	mu        sync.Mutex
+	file      *os.File
+	owned     bool
+	encryptor *encryptor
+}
+
+// AuditLogOption configures an AuditLog.
+type AuditLogOption func(*AuditLog) error
+
+// WithAuditEncryptionKey encrypts the entries of the log with key, encrypting those already
+// written in plain text on open. A nil key leaves the log unencrypted.
+func WithAuditEncryptionKey(key []byte) AuditLogOption {
+	return func(a *AuditLog) error {
+		encryptor, err := encryptorFor(key)
+		a.encryptor = encryptor
+		return err
+	}
+}
+
+// AuditLogFromFile opens the audit log at path, creating it if needed.
+func AuditLogFromFile(path string, options ...AuditLogOption) (*AuditLog, func(), error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %v, %s", err, path)
+	}
+
+	auditLog := NewAuditLog(file)
+	auditLog.owned = true
+
+	if err := auditLog.open(options); err != nil {
+		auditLog.Close()
+		return nil, nil, err
+	}
+
+	closeFunc := func() {
+		auditLog.Close()
+	}
+
+	return auditLog, closeFunc, nil
+}
+
+// NewAuditLog creates an AuditLog appending to file.
+func NewAuditLog(file *os.File) *AuditLog {
+	return &AuditLog{file: file}
+}
+
+// open applies options to the log. If it has a key, it checks the key opens every entry and
+// encrypts any plain ones.
+func (a *AuditLog) open(options []AuditLogOption) error {
+	for _, option := range options {
+		if err := option(a); err != nil {
+			return err
+		}
+	}
+
+	if a.encryptor == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, err := a.entries(""); err != nil {
+		return err
+	}
+
+	lines, err := a.lines()
+
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		if isPlainLine(line) {
+			return a.rewrite(a.encryptor)
+		}
+	}
+
+	return nil
+}
+
+// Append adds entry to the end of the log.
+func (a *AuditLog) Append(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+
+	if err != nil {
+		return fmt.Errorf("problem encoding audit entry, %v", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if line, err = encryptLine(line, a.encryptor); err != nil {
+		return fmt.Errorf("problem encrypting audit entry, %v", err)
+	}
+
+	if err := a.refresh(); err != nil {
+		return err
+	}
+
+	_, err = a.file.Write(append(line, '\n'))
+
+	if err != nil {
+		return fmt.Errorf("problem writing audit entry to %s, %v", a.file.Name(), err)
+	}
+
+	return nil
+}
+
+// Entries returns the logged entries for player in the order they happened, or every entry if player is empty.
+func (a *AuditLog) Entries(player string) ([]AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.refresh(); err != nil {
+		return nil, err
+	}
+
+	return a.entries(player)
+}
+
+// Rekey rewrites the log with its entries encrypted with key, or in plain text if key is nil.
+// The log is replaced as a whole, so it is never left half rewritten.
+func (a *AuditLog) Rekey(key []byte) error {
+	encryptor, err := encryptorFor(key)
+
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.refresh(); err != nil {
+		return err
+	}
+
+	return a.rewrite(encryptor)
+}
+
+// Close closes the file if the log opened it itself.
+func (a *AuditLog) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.owned {
+		return nil
+	}
+
+	return a.file.Close()
+}
+
+func (a *AuditLog) entries(player string) ([]AuditEntry, error) {
+	lines, err := a.lines()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []AuditEntry{}
+
+	for _, line := range lines {
+		var entry AuditEntry
+
+		plain, err := decryptLine(line, a.encryptor)
+
+		if err != nil {
+			return nil, fmt.Errorf("problem decrypting audit log %s, %w", a.file.Name(), err)
+		}
+
+		if err := json.Unmarshal(plain, &entry); err != nil {
+			return nil, fmt.Errorf("problem parsing audit log %s, %v", a.file.Name(), err)
+		}
+
+		if player == "" || entry.Player == player {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// lines returns the lines of the log as they are in the file.
+func (a *AuditLog) lines() ([][]byte, error) {
+	var lines [][]byte
+	scanner := bufio.NewScanner(io.NewSectionReader(a.file, 0, math.MaxInt64))
+
+	for scanner.Scan() {
+		lines = append(lines, append([]byte{}, scanner.Bytes()...))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("problem reading audit log %s, %v", a.file.Name(), err)
+	}
+
+	return lines, nil
+}
+
+// rewrite replaces the log with its entries encrypted with encryptor, and appends to the new
+// file from then on.
+func (a *AuditLog) rewrite(encryptor *encryptor) error {
+	entries, err := a.entries("")
+
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+
+	for _, entry := range entries {
+		line, _ := json.Marshal(entry)
+
+		if line, err = encryptLine(line, encryptor); err != nil {
+			return fmt.Errorf("problem encrypting audit entry, %v", err)
+		}
+
+		data = append(append(data, line...), '\n')
+	}
+
+	replacement := newTape(a.file)
+
+	if _, err := replacement.Write(data); err != nil {
+		return err
+	}
+
+	replacement.Close()
+	a.encryptor = encryptor
+
+	return a.reopen()
+}
+
+// refresh reopens the log if another process has replaced it, by rekeying it say.
+func (a *AuditLog) refresh() error {
+	onDisk, err := os.Stat(a.file.Name())
+
+	if err != nil {
+		return fmt.Errorf("problem getting file info from file %s, %v", a.file.Name(), err)
+	}
+
+	open, err := a.file.Stat()
+
+	if err != nil {
+		return fmt.Errorf("problem getting file info from file %s, %v", a.file.Name(), err)
+	}
+
+	if os.SameFile(onDisk, open) {
+		return nil
+	}
+
+	return a.reopen()
+}
+
+// reopen appends to whatever file is now at the log's path.
+func (a *AuditLog) reopen() error {
+	file, err := os.OpenFile(a.file.Name(), os.O_RDWR|os.O_APPEND, 0666)
+
+	if err != nil {
+		return fmt.Errorf("problem reopening %s, %v", a.file.Name(), err)
+	}
+
+	if a.owned {
+		a.file.Close()
+	}
+
+	a.file = file
+	a.owned = true
+
+	return nil
+}
+
+// storeAudit records the changes a store makes in an audit log, on behalf of the origin in the
+// context of each change or origin if there is none. The zero value records nothing.
+type storeAudit struct {
+	log    *AuditLog
+	origin Origin
+}
+
+// record appends an entry for a change made at at. The change has already been made, so a
+// failure to audit it is logged rather than reported as a failure of the change.
+func (s storeAudit) record(ctx context.Context, at time.Time, action, player, detail string) {
+	if s.log == nil {
+		return
+	}
+
+	origin, ok := OriginFromContext(ctx)
+
+	if !ok {
+		origin = s.origin
+	}
+
+	if origin.RequestID == "" {
+		origin.RequestID = NewRequestID()
+	}
+
+	err := s.log.Append(AuditEntry{
+		Time:         at,
+		Action:       action,
+		Player:       player,
+		Detail:       detail,
+		Actor:        origin.Actor,
+		ClaimedActor: origin.ClaimedActor,
+		Source:       origin.Source,
+		RequestID:    origin.RequestID,
+	})
+
+	if err != nil {
+		log.Printf("could not audit %s for %q, %v", action, player, err)
+	}
+}
+
+// NewRequestID returns a random identifier for correlating a mutation with its audit entry.
+func NewRequestID() string {
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// tokenActor identifies the holder of token without writing the token itself to the audit log.
+func tokenActor(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,172 @@
+package poker
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+// cliOrigin is who changes made without an origin in their context are audited as in tests.
+var cliOrigin = Origin{Actor: "ruth", Source: AuditSourceCLI}
+
+// newAuditedStore creates a FileSystemPlayerStore auditing its changes to a fresh audit log.
+func newAuditedStore(t testing.TB, league string) (*FileSystemPlayerStore, *AuditLog) {
+	t.Helper()
+
+	database, cleanDatabase := createTempFile(t, league)
+	t.Cleanup(cleanDatabase)
+
+	auditFile, cleanAudit := createTempFile(t, "")
+	t.Cleanup(cleanAudit)
+
+	auditLog := NewAuditLog(auditFile)
+	store, err := NewFileSystemPlayerStore(database, WithStoreAuditLog(auditLog, cliOrigin))
+	assertNoError(t, err)
+
+	return store, auditLog
+}
+
+func TestAuditLog(t *testing.T) {
+	t.Run("returns appended entries in order", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		auditLog := NewAuditLog(file)
+
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Chris", Source: AuditSourceCLI}))
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Cleo", Source: AuditSourceHTTP}))
+
+		got, err := auditLog.Entries("")
+		assertNoError(t, err)
+
+		assertAuditPlayers(t, got, "Chris", "Cleo")
+	})
+
+	t.Run("filters entries by player", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		auditLog := NewAuditLog(file)
+
+		auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Chris"})
+		auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Cleo"})
+		auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Chris"})
+
+		got, err := auditLog.Entries("Chris")
+		assertNoError(t, err)
+
+		assertAuditPlayers(t, got, "Chris", "Chris")
+	})
+
+	t.Run("keeps entries written before reopening", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		auditLog, closeAudit, err := AuditLogFromFile(path)
+		assertNoError(t, err)
+		auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Chris"})
+		closeAudit()
+
+		auditLog, closeAudit, err = AuditLogFromFile(path)
+		assertNoError(t, err)
+		defer closeAudit()
+		auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Cleo"})
+
+		got, err := auditLog.Entries("")
+		assertNoError(t, err)
+
+		assertAuditPlayers(t, got, "Chris", "Cleo")
+	})
+}
+
+func TestStoreAudit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("audits every change to the league", func(t *testing.T) {
+		store, auditLog := newAuditedStore(t, `[]`)
+
+		assertNoError(t, store.RecordWin(ctx, "Chris"))
+		assertNoError(t, store.RecordWins(ctx, []string{"Cleo", "Chris"}))
+		_, err := store.RecordWinOnce(ctx, "Pepper", "abc")
+		assertNoError(t, err)
+		assertNoError(t, store.ReplaceLeague(ctx, League{{"Chris", 2}}))
+		_, err = store.NewSeason(ctx)
+		assertNoError(t, err)
+		assertNoError(t, store.Rekey(ctx, make([]byte, EncryptionKeySize)))
+		assertNoError(t, store.RecordTransaction(ctx, Deposit("Chris", 50)))
+
+		entries, err := auditLog.Entries("")
+		assertNoError(t, err)
+
+		assertAuditActions(t, entries, AuditActionWin, AuditActionWin, AuditActionWin, AuditActionWin, AuditActionImport, AuditActionSeason, AuditActionRekey, AuditActionTransaction)
+		assertAuditPlayers(t, entries, "Chris", "Cleo", "Chris", "Pepper", "", "", "", "Chris")
+
+		if detail := entries[len(entries)-1].Detail; detail != "deposit of 50 chips" {
+			t.Errorf("got transaction detail %q want %q", detail, "deposit of 50 chips")
+		}
+
+		for _, entry := range entries {
+			if entry.Actor != cliOrigin.Actor || entry.Source != cliOrigin.Source || entry.RequestID == "" {
+				t.Errorf("unexpected audit entry %+v", entry)
+			}
+		}
+	})
+
+	t.Run("audits on behalf of the origin of the change", func(t *testing.T) {
+		store, auditLog := newAuditedStore(t, `[]`)
+
+		origin := Origin{Actor: "10.0.0.1:1234", ClaimedActor: "dealer", Source: AuditSourceHTTP, RequestID: "req-1"}
+		assertNoError(t, store.RecordWin(ContextWithOrigin(ctx, origin), "Chris"))
+
+		entries, err := auditLog.Entries("Chris")
+		assertNoError(t, err)
+		assertAuditPlayers(t, entries, "Chris")
+
+		got := Origin{entries[0].Actor, entries[0].ClaimedActor, entries[0].Source, entries[0].RequestID}
+		if got != origin {
+			t.Errorf("got origin %+v want %+v", got, origin)
+		}
+	})
+
+	t.Run("does not audit changes that failed", func(t *testing.T) {
+		store, auditLog := newAuditedStore(t, `[]`)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		if err := store.RecordWin(cancelled, "Chris"); err == nil {
+			t.Fatal("expected an error recording a win with a cancelled context")
+		}
+
+		entries, err := auditLog.Entries("")
+		assertNoError(t, err)
+		assertAuditPlayers(t, entries)
+	})
+}
+
+func assertAuditActions(t testing.TB, entries []AuditEntry, want ...string) {
+	t.Helper()
+
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries want %d, %v", len(entries), len(want), entries)
+	}
+
+	for i, entry := range entries {
+		if entry.Action != want[i] {
+			t.Errorf("audit entry %d is a %q want %q", i, entry.Action, want[i])
+		}
+	}
+}
+
+func assertAuditPlayers(t testing.TB, entries []AuditEntry, want ...string) {
+	t.Helper()
+
+	if len(entries) != len(want) {
+		t.Fatalf("got %d audit entries want %d, %v", len(entries), len(want), entries)
+	}
+
+	for i, entry := range entries {
+		if entry.Player != want[i] {
+			t.Errorf("audit entry %d is for %q want %q", i, entry.Player, want[i])
+		}
+	}
+}
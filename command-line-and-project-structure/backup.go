@@ -0,0 +1,189 @@
+package poker
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	backupTimeFormat  = "20060102T150405Z"
+	checksumExtension = ".sha256"
+)
+
+// Backup copies the database at dbPath into dir as a timestamped snapshot with a checksum file beside it.
+// The database is read under its lock so the snapshot is of a single moment.
+func Backup(dbPath, dir string, now time.Time) (string, error) {
+	var data []byte
+
+	err := withDatabaseLock(dbPath, lockShared, func() error {
+		var err error
+
+		if data, err = os.ReadFile(dbPath); err != nil {
+			return fmt.Errorf("problem reading %s for backup, %v", dbPath, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return "", fmt.Errorf("problem creating backup directory %s, %v", dir, err)
+	}
+
+	name := fmt.Sprintf("%s.%s.bak", filepath.Base(dbPath), now.UTC().Format(backupTimeFormat))
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		return "", fmt.Errorf("problem writing backup %s, %v", path, err)
+	}
+
+	checksum := fmt.Sprintf("%s  %s\n", checksumOf(data), name)
+
+	if err := os.WriteFile(path+checksumExtension, []byte(checksum), 0666); err != nil {
+		return "", fmt.Errorf("problem writing checksum for backup %s, %v", path, err)
+	}
+
+	return path, nil
+}
+
+// Restore replaces the database at dbPath with the snapshot at backupPath once its checksum has been
+// verified. The database lock is held while it is replaced, so no store can write over the restored
+// league. options are those the database is
+// opened with: an encrypted snapshot is decrypted with their key to check it before it is restored,
+// and the restore is audited if they include an audit log.
+func Restore(ctx context.Context, backupPath, dbPath string, options ...FileSystemPlayerStoreOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f := &FileSystemPlayerStore{now: time.Now}
+
+	for _, option := range options {
+		if err := option(f); err != nil {
+			return err
+		}
+	}
+
+	data, err := readBackup(backupPath)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkSnapshot(data, f.encryptor); err != nil {
+		return fmt.Errorf("backup %s cannot be restored, %w", backupPath, err)
+	}
+
+	err = withDatabaseLock(dbPath, lockExclusive, func() error {
+		if err := replaceFile(dbPath, data); err != nil {
+			return fmt.Errorf("problem restoring %s, %v", dbPath, err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	f.audit.record(ctx, f.now(), AuditActionRestore, "", fmt.Sprintf("restored %s", filepath.Base(backupPath)))
+	return nil
+}
+
+// readBackup reads the snapshot at backupPath, checking it against the first line of its checksum
+// file. Backups taken before idempotency keys were kept in the database have a second line for the
+// keys beside them, which are no longer restored.
+func readBackup(backupPath string) ([]byte, error) {
+	data, err := os.ReadFile(backupPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem reading backup %s, %v", backupPath, err)
+	}
+
+	checksum, err := os.ReadFile(backupPath + checksumExtension)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem reading checksum for backup %s, %v", backupPath, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(checksum)), "\n")
+
+	if !matchesChecksum(lines[0], data) {
+		return nil, fmt.Errorf("backup %s does not match its checksum", backupPath)
+	}
+
+	return data, nil
+}
+
+// checkSnapshot reports whether data is a database that can be read, decrypting it with
+// encryptor first if it is encrypted, so a corrupt snapshot is never restored.
+func checkSnapshot(data []byte, encryptor *encryptor) error {
+	data, err := decryptDatabase(data, encryptor)
+
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := loadDatabase(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("not a valid database, %v", err)
+	}
+
+	return nil
+}
+
+// matchesChecksum reports whether a line of a checksum file is the checksum of data.
+func matchesChecksum(line string, data []byte) bool {
+	fields := strings.Fields(line)
+	return len(fields) > 0 && fields[0] == checksumOf(data)
+}
+
+// replaceFile atomically replaces the file at path with data, creating it if it doesn't exist.
+func replaceFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	t := newTape(file)
+	defer t.Close()
+
+	_, err = t.Write(data)
+	return err
+}
+
+// withDatabaseLock runs fn holding the lock stores take on the database at dbPath.
+func withDatabaseLock(dbPath string, how lockType, fn func() error) error {
+	lock, err := os.OpenFile(dbPath+".lock", os.O_RDWR|os.O_CREATE, 0666)
+
+	if err != nil {
+		return fmt.Errorf("problem opening lock file for %s, %v", dbPath, err)
+	}
+
+	defer lock.Close()
+
+	if err := lockFile(lock, how); err != nil {
+		return fmt.Errorf("problem locking %s, %v", lock.Name(), err)
+	}
+
+	defer unlockFile(lock)
+
+	return fn()
+}
+
+func checksumOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
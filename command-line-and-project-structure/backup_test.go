@@ -0,0 +1,152 @@
+package poker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "game.db.json")
+	backups := filepath.Join(dir, "backups")
+	now := time.Date(2026, 10, 19, 9, 12, 17, 0, time.UTC)
+
+	os.WriteFile(dbPath, []byte(`[{"Name":"Chris","Wins":32}]`), 0666)
+
+	store, close, err := FileSystemFileStoreFromFile(dbPath)
+	assertNoError(t, err)
+	recordWinOnce(t, store, "Chris", "abc")
+	close()
+
+	path, err := Backup(dbPath, backups, now)
+	assertNoError(t, err)
+
+	t.Run("names the snapshot after the time it was taken", func(t *testing.T) {
+		if !strings.HasSuffix(path, "game.db.json.20261019T091217Z.bak") {
+			t.Errorf("unexpected backup name %s", path)
+		}
+	})
+
+	t.Run("restores the snapshot over the database", func(t *testing.T) {
+		os.WriteFile(dbPath, []byte(`[]`), 0666)
+
+		assertNoError(t, Restore(context.Background(), path, dbPath))
+
+		store, close, err := FileSystemFileStoreFromFile(dbPath)
+		assertNoError(t, err)
+		defer close()
+
+		assertScoreEquals(t, getScore(t, store, "Chris"), 33)
+	})
+
+	t.Run("restores the idempotency keys with the snapshot", func(t *testing.T) {
+		store, close, err := FileSystemFileStoreFromFile(dbPath)
+		assertNoError(t, err)
+		defer close()
+
+		assertRecorded(t, recordWinOnce(t, store, "Chris", "abc"), false)
+	})
+
+	t.Run("audits the restore", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		auditLog := NewAuditLog(file)
+		assertNoError(t, Restore(context.Background(), path, dbPath, WithStoreAuditLog(auditLog, cliOrigin)))
+
+		entries, err := auditLog.Entries("")
+		assertNoError(t, err)
+		assertAuditActions(t, entries, AuditActionRestore)
+
+		if entries[0].Actor != cliOrigin.Actor || entries[0].Detail != "restored "+filepath.Base(path) {
+			t.Errorf("unexpected audit entry %+v", entries[0])
+		}
+	})
+
+	t.Run("waits for stores to stop writing before restoring", func(t *testing.T) {
+		lock, err := os.OpenFile(dbPath+".lock", os.O_RDWR|os.O_CREATE, 0666)
+		assertNoError(t, err)
+		defer lock.Close()
+
+		assertNoError(t, lockFile(lock, lockExclusive))
+
+		restored := make(chan error)
+		go func() { restored <- Restore(context.Background(), path, dbPath) }()
+
+		select {
+		case <-restored:
+			t.Fatal("restored while another store held the lock")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		unlockFile(lock)
+		assertNoError(t, <-restored)
+	})
+
+	t.Run("refuses a snapshot that does not match its checksum", func(t *testing.T) {
+		os.WriteFile(path, []byte(`[{"Name":"Chris","Wins":99}]`), 0666)
+
+		if err := Restore(context.Background(), path, dbPath); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestRestoreEncrypted(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "game.db.json")
+	key := make([]byte, EncryptionKeySize)
+
+	store, close, err := FileSystemFileStoreFromFile(dbPath, WithEncryptionKey(key))
+	assertNoError(t, err)
+	recordWin(t, store, "Chris")
+	close()
+
+	path, err := Backup(dbPath, filepath.Join(dir, "backups"), time.Now())
+	assertNoError(t, err)
+
+	t.Run("refuses an encrypted snapshot without a key", func(t *testing.T) {
+		if err := Restore(ctx, path, dbPath); !errors.Is(err, ErrNoKey) {
+			t.Errorf("got error %v want %v", err, ErrNoKey)
+		}
+	})
+
+	t.Run("refuses an encrypted snapshot with the wrong key", func(t *testing.T) {
+		wrong := bytes.Repeat([]byte{1}, EncryptionKeySize)
+
+		if err := Restore(ctx, path, dbPath, WithEncryptionKey(wrong)); !errors.Is(err, ErrWrongKey) {
+			t.Errorf("got error %v want %v", err, ErrWrongKey)
+		}
+	})
+
+	t.Run("refuses a corrupt encrypted snapshot that matches its checksum", func(t *testing.T) {
+		corrupt := filepath.Join(dir, "corrupt.bak")
+		data, err := os.ReadFile(path)
+		assertNoError(t, err)
+
+		data[len(data)-1] ^= 0xff
+		os.WriteFile(corrupt, data, 0666)
+		os.WriteFile(corrupt+checksumExtension, []byte(checksumOf(data)+"  corrupt.bak\n"), 0666)
+
+		if err := Restore(ctx, corrupt, dbPath, WithEncryptionKey(key)); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("restores an encrypted snapshot with its key", func(t *testing.T) {
+		assertNoError(t, Restore(ctx, path, dbPath, WithEncryptionKey(key)))
+
+		store, close, err := FileSystemFileStoreFromFile(dbPath, WithEncryptionKey(key))
+		assertNoError(t, err)
+		defer close()
+
+		assertScoreEquals(t, getScore(t, store, "Chris"), 1)
+	})
+}
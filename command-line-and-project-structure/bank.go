@@ -0,0 +1,213 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Chips is an amount of chips.
+type Chips int
+
+// Kinds of chip transaction. Deposits and payouts add chips to a player's balance,
+// withdrawals and buy-ins take them away.
+const (
+	TransactionDeposit    = "deposit"
+	TransactionWithdrawal = "withdrawal"
+	TransactionBuyIn      = "buy-in"
+	TransactionPayout     = "payout"
+)
+
+// ErrInsufficientFunds is wrapped by every InsufficientFundsError and InsufficientPotError.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// InsufficientFundsError is returned when a transaction would overdraw a player.
+type InsufficientFundsError struct {
+	Player  string
+	Balance Chips
+	Amount  Chips
+}
+
+func (e InsufficientFundsError) Error() string {
+	return fmt.Sprintf("%s cannot spend %d chips with a balance of %d, %v", e.Player, e.Amount, e.Balance, ErrInsufficientFunds)
+}
+
+func (e InsufficientFundsError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// InsufficientPotError is returned when a payout would pay out more of a game than was bought
+// into it.
+type InsufficientPotError struct {
+	Game   string
+	Pot    Chips
+	Amount Chips
+}
+
+func (e InsufficientPotError) Error() string {
+	return fmt.Sprintf("cannot pay out %d chips from %s with %d left in the pot, %v", e.Amount, e.Game, e.Pot, ErrInsufficientFunds)
+}
+
+func (e InsufficientPotError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// Transaction moves chips into or out of a player's balance. Amount is always
+// positive, Kind says which way it goes. Game names the game of a buy-in or payout.
+type Transaction struct {
+	Player string    `json:"player"`
+	Kind   string    `json:"kind"`
+	Amount Chips     `json:"amount"`
+	Game   string    `json:"game,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Deposit returns a transaction adding amount to player's balance.
+func Deposit(player string, amount Chips) Transaction {
+	return Transaction{Player: player, Kind: TransactionDeposit, Amount: amount}
+}
+
+// Withdrawal returns a transaction taking amount out of player's balance.
+func Withdrawal(player string, amount Chips) Transaction {
+	return Transaction{Player: player, Kind: TransactionWithdrawal, Amount: amount}
+}
+
+// BuyIn returns a transaction paying amount from player's balance to sit in game.
+func BuyIn(player, game string, amount Chips) Transaction {
+	return Transaction{Player: player, Kind: TransactionBuyIn, Amount: amount, Game: game}
+}
+
+// Payout returns a transaction paying amount won in game into player's balance.
+func Payout(player, game string, amount Chips) Transaction {
+	return Transaction{Player: player, Kind: TransactionPayout, Amount: amount, Game: game}
+}
+
+// describe says what the transaction was, e.g. "buy-in of 50 chips for friday".
+func (t Transaction) describe() string {
+	description := fmt.Sprintf("%s of %s", t.Kind, plural(int(t.Amount), "chip"))
+
+	if t.Game != "" {
+		description += " for " + t.Game
+	}
+
+	return description
+}
+
+// change returns how the transaction changes the player's balance.
+func (t Transaction) change() Chips {
+	if t.Kind == TransactionWithdrawal || t.Kind == TransactionBuyIn {
+		return -t.Amount
+	}
+
+	return t.Amount
+}
+
+func (t Transaction) validate() error {
+	switch t.Kind {
+	case TransactionDeposit, TransactionWithdrawal, TransactionBuyIn, TransactionPayout:
+	default:
+		return fmt.Errorf("unknown transaction kind %q", t.Kind)
+	}
+
+	if t.Player == "" {
+		return errors.New("a transaction needs a player")
+	}
+
+	if t.Amount <= 0 {
+		return fmt.Errorf("a transaction needs a positive amount, got %d", t.Amount)
+	}
+
+	if t.Game == "" && (t.Kind == TransactionBuyIn || t.Kind == TransactionPayout) {
+		return fmt.Errorf("a %s needs a game", t.Kind)
+	}
+
+	return nil
+}
+
+// Ledger is a list of chip transactions, oldest first.
+type Ledger []Transaction
+
+// Balance adds up the chips player has.
+func (l Ledger) Balance(player string) Chips {
+	var balance Chips
+
+	for _, t := range l {
+		if t.Player == player {
+			balance += t.change()
+		}
+	}
+
+	return balance
+}
+
+// Of returns the transactions of player.
+func (l Ledger) Of(player string) Ledger {
+	transactions := Ledger{}
+
+	for _, t := range l {
+		if t.Player == player {
+			transactions = append(transactions, t)
+		}
+	}
+
+	return transactions
+}
+
+// Pot adds up the chips bought into game that have not been paid out yet.
+func (l Ledger) Pot(game string) Chips {
+	var pot Chips
+
+	for _, t := range l {
+		if t.Game == game {
+			pot -= t.change()
+		}
+	}
+
+	return pot
+}
+
+// account returns the Account of player in the ledger.
+func (l Ledger) account(player string) Account {
+	return Account{Player: player, Balance: l.Balance(player), Transactions: l.Of(player)}
+}
+
+// record returns the ledger with t added, refusing transactions that are invalid, would
+// overdraw the player or would pay out more of a game than was bought into it.
+func (l Ledger) record(t Transaction) (Ledger, error) {
+	if err := t.validate(); err != nil {
+		return l, err
+	}
+
+	if t.Kind == TransactionPayout {
+		if pot := l.Pot(t.Game); t.Amount > pot {
+			return l, InsufficientPotError{t.Game, pot, t.Amount}
+		}
+	}
+
+	balance := l.Balance(t.Player)
+
+	if balance+t.change() < 0 {
+		return l, InsufficientFundsError{t.Player, balance, t.Amount}
+	}
+
+	return append(l, t), nil
+}
+
+// Account is a player's chip balance with the transactions that make it up.
+type Account struct {
+	Player       string `json:"player"`
+	Balance      Chips  `json:"balance"`
+	Transactions Ledger `json:"transactions"`
+}
+
+// BankPlayerStore is a PlayerStore that also keeps each player's chips.
+type BankPlayerStore interface {
+	PlayerStore
+	// RecordTransaction adds t to the ledger, timed now if it has no time, failing
+	// with an InsufficientFundsError if it would overdraw the player or an
+	// InsufficientPotError if it would pay out more of a game than was bought into it.
+	RecordTransaction(ctx context.Context, t Transaction) error
+	// GetAccount returns player's balance and transactions.
+	GetAccount(ctx context.Context, player string) (Account, error)
+}
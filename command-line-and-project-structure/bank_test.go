@@ -0,0 +1,175 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLedger(t *testing.T) {
+	cases := []struct {
+		desc         string
+		transactions []Transaction
+		want         Chips
+	}{
+		{"deposits add chips", []Transaction{Deposit("Cleo", 100), Deposit("Cleo", 50)}, 150},
+		{"withdrawals take chips", []Transaction{Deposit("Cleo", 100), Withdrawal("Cleo", 30)}, 70},
+		{"buy-ins take chips and payouts add them", []Transaction{Deposit("Cleo", 100), Deposit("Chris", 150), BuyIn("Cleo", "friday", 100), BuyIn("Chris", "friday", 150), Payout("Cleo", "friday", 250)}, 250},
+		{"other players do not count", []Transaction{Deposit("Cleo", 100), Deposit("Chris", 20)}, 100},
+		{"spending everything leaves nothing", []Transaction{Deposit("Cleo", 100), BuyIn("Cleo", "friday", 100)}, 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			ledger := Ledger{}
+
+			for _, transaction := range c.transactions {
+				var err error
+				ledger, err = ledger.record(transaction)
+				assertNoError(t, err)
+			}
+
+			assertBalance(t, ledger.Balance("Cleo"), c.want)
+		})
+	}
+
+	t.Run("refuses to overdraw a player", func(t *testing.T) {
+		ledger, _ := Ledger{}.record(Deposit("Cleo", 50))
+
+		ledger, err := ledger.record(BuyIn("Cleo", "friday", 80))
+
+		var fundsErr InsufficientFundsError
+		if !errors.As(err, &fundsErr) {
+			t.Fatalf("expected an InsufficientFundsError but got %v", err)
+		}
+
+		if fundsErr != (InsufficientFundsError{"Cleo", 50, 80}) {
+			t.Errorf("unexpected error %+v", fundsErr)
+		}
+
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected %v to be %v", err, ErrInsufficientFunds)
+		}
+
+		assertBalance(t, ledger.Balance("Cleo"), 50)
+	})
+
+	t.Run("refuses to pay out more than was bought into a game", func(t *testing.T) {
+		ledger := Ledger{}
+
+		for _, transaction := range []Transaction{
+			Deposit("Cleo", 100),
+			Deposit("Chris", 100),
+			BuyIn("Cleo", "friday", 50),
+			BuyIn("Chris", "friday", 50),
+			BuyIn("Chris", "saturday", 50),
+			Payout("Cleo", "friday", 60),
+		} {
+			var err error
+			ledger, err = ledger.record(transaction)
+			assertNoError(t, err)
+		}
+
+		ledger, err := ledger.record(Payout("Chris", "friday", 50))
+
+		var potErr InsufficientPotError
+		if !errors.As(err, &potErr) {
+			t.Fatalf("expected an InsufficientPotError but got %v", err)
+		}
+
+		if potErr != (InsufficientPotError{"friday", 40, 50}) {
+			t.Errorf("unexpected error %+v", potErr)
+		}
+
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected %v to be %v", err, ErrInsufficientFunds)
+		}
+
+		assertBalance(t, ledger.Balance("Chris"), 0)
+		assertBalance(t, ledger.Pot("friday"), 40)
+	})
+
+	invalid := []Transaction{
+		Deposit("Cleo", 0),
+		Payout("Cleo", "friday", -10),
+		Payout("Cleo", "", 10),
+		Deposit("", 10),
+		{Player: "Cleo", Kind: "loan", Amount: 10},
+	}
+
+	for _, transaction := range invalid {
+		t.Run("refuses "+transaction.Kind+" transactions that are not valid", func(t *testing.T) {
+			if _, err := (Ledger{}).record(transaction); err == nil {
+				t.Errorf("expected an error recording %+v but didn't get one", transaction)
+			}
+		})
+	}
+}
+
+func TestFileSystemStoreBank(t *testing.T) {
+	t.Run("keeps accounts across restarts", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		now := time.Date(2026, time.October, 2, 20, 0, 0, 0, time.UTC)
+		store.now = func() time.Time { return now }
+
+		recordTransaction(t, store, Deposit("Cleo", 100))
+		recordTransaction(t, store, BuyIn("Cleo", "friday", 40))
+
+		store, err = NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		account := getAccount(t, store, "Cleo")
+
+		assertBalance(t, account.Balance, 60)
+
+		if len(account.Transactions) != 2 || account.Transactions[1].Game != "friday" || !account.Transactions[1].Time.Equal(now) {
+			t.Errorf("unexpected transactions %+v", account.Transactions)
+		}
+	})
+
+	t.Run("does not save a buy-in that would overdraw", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		recordTransaction(t, store, Deposit("Cleo", 10))
+
+		err = store.RecordTransaction(context.Background(), BuyIn("Cleo", "friday", 20))
+
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Fatalf("got %v want %v", err, ErrInsufficientFunds)
+		}
+
+		store, err = NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		assertBalance(t, getAccount(t, store, "Cleo").Balance, 10)
+	})
+}
+
+func recordTransaction(t testing.TB, store BankPlayerStore, transaction Transaction) {
+	t.Helper()
+	assertNoError(t, store.RecordTransaction(context.Background(), transaction))
+}
+
+func getAccount(t testing.TB, store BankPlayerStore, player string) Account {
+	t.Helper()
+	account, err := store.GetAccount(context.Background(), player)
+	assertNoError(t, err)
+	return account
+}
+
+func assertBalance(t testing.TB, got, want Chips) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got balance %d want %d", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchPlayerStore is a PlayerStore that can record many wins at once, all of them or none.
+type BatchPlayerStore interface {
+	PlayerStore
+	// RecordWins records a win for each name, in order, in a single transaction.
+	RecordWins(ctx context.Context, names []string) error
+}
+
+// LineError is a line of a batch that could not be read.
+type LineError struct {
+	Line  int
+	Input string
+	Err   error
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %q, %v", e.Line, e.Input, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError lists every line of a batch that could not be read. Nothing in a batch
+// with errors is recorded.
+type BatchError []LineError
+
+func (e BatchError) Error() string {
+	lines := make([]string, len(e))
+
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+
+	return fmt.Sprintf("%s, nothing was recorded\n%s", plural(len(e), "bad line"), strings.Join(lines, "\n"))
+}
+
+// ErrBatchUnsupported is returned when recording a batch in a store that cannot record all of
+// its wins at once, which could leave a failed batch half recorded.
+var ErrBatchUnsupported = errors.New("the player store cannot record a batch of wins all at once")
+
+// errOnlyWins is reported for score and league lines, which make no sense part way through a batch.
+var errOnlyWins = errors.New(`only "{name} wins" can be run in a batch`)
+
+// PlayBatch reads "{name} wins" commands until the end of the input and records them
+// together, then prints a summary and the resulting league. Blank lines and lines starting
+// with # are skipped. If any line is not a win nothing is recorded and a BatchError names
+// every bad line. With dryRun the league is printed as it would be, without recording anything.
+// Batches can only be recorded in a BatchPlayerStore, otherwise ErrBatchUnsupported is returned.
+func (c *CLI) PlayBatch(dryRun bool) error {
+	winners, err := c.readBatch()
+
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	var league League
+
+	if dryRun {
+		league, err = c.playerStore.GetLeague(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		league = withWins(league, winners)
+	} else {
+		if err := recordWins(ctx, c.playerStore, winners); err != nil {
+			return err
+		}
+
+		league, err = c.playerStore.GetLeague(ctx)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	c.printBatchSummary(winners, league, dryRun)
+	return nil
+}
+
+func (c *CLI) readBatch() ([]string, error) {
+	var winners []string
+	var bad BatchError
+
+	for line := 1; c.in.Scan(); line++ {
+		input := strings.TrimSpace(c.in.Text())
+
+		if input == "" || strings.HasPrefix(input, "#") {
+			continue
+		}
+
+		command, err := ParseCommand(input)
+
+		if err == nil && command.Action != CommandWins {
+			err = errOnlyWins
+		}
+
+		if err != nil {
+			bad = append(bad, LineError{Line: line, Input: input, Err: err})
+			continue
+		}
+
+		winners = append(winners, command.Player)
+	}
+
+	if err := c.in.Err(); err != nil {
+		return nil, fmt.Errorf("problem reading batch, %v", err)
+	}
+
+	if len(bad) > 0 {
+		return nil, bad
+	}
+
+	return winners, nil
+}
+
+// recordWins records every win in one transaction, refusing stores that cannot.
+func recordWins(ctx context.Context, store PlayerStore, winners []string) error {
+	batch, ok := store.(BatchPlayerStore)
+
+	if !ok {
+		return ErrBatchUnsupported
+	}
+
+	return batch.RecordWins(ctx, winners)
+}
+
+// withWins returns a copy of league with a win added for each of winners.
+func withWins(league League, winners []string) League {
+	league = append(League{}, league...)
+
+	for _, winner := range winners {
+		if player := league.Find(winner); player != nil {
+			player.Wins++
+		} else {
+			league = append(league, Player{winner, 1})
+		}
+	}
+
+	sortLeague(league)
+	return league
+}
+
+func (c *CLI) printBatchSummary(winners []string, league League, dryRun bool) {
+	wins := map[string]int{}
+
+	for _, winner := range winners {
+		wins[winner]++
+	}
+
+	players := make([]string, 0, len(wins))
+
+	for player := range wins {
+		players = append(players, player)
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		if wins[players[i]] != wins[players[j]] {
+			return wins[players[i]] > wins[players[j]]
+		}
+		return players[i] < players[j]
+	})
+
+	verb := "Recorded"
+
+	if dryRun {
+		verb = "Would record"
+	}
+
+	fmt.Fprintf(c.out, "%s %s for %s\n", verb, plural(len(winners), "win"), plural(len(players), "player"))
+
+	for _, player := range players {
+		fmt.Fprintf(c.out, "  %s +%d\n", player, wins[player])
+	}
+
+	fmt.Fprintln(c.out)
+	fmt.Fprintln(c.out, formatLeague(league))
+}
+
+// plural counts n of noun, adding an s unless there is exactly one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+
+	return fmt.Sprintf("%d %ss", n, noun)
+}
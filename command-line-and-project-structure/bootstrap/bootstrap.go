@@ -0,0 +1,371 @@
+// Package bootstrap opens the league and wires up the server for every mode of the poker
+// command, so they all find their files, read their flags and fail in the same way.
+package bootstrap
+
+import (
+	poker "command-line-and-project-structure"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+)
+
+// Files kept in the working directory.
+const (
+	DBFileName          = "game.db.json"
+	AuditFileName       = "game.audit.jsonl"
+	TournamentsFileName = "game.tournaments.json"
+	DeadLettersFileName = "game.webhooks.dead.jsonl"
+)
+
+// Environment variables holding the secrets the server signs and checks requests with.
+const (
+	WebhookSecretEnv = "POKER_WEBHOOK_SECRET"
+	CommandSecretEnv = "POKER_COMMAND_SECRET"
+)
+
+// Config says where the league is kept and what the server does besides serving it.
+// The zero value keeps the league in DBFileName and serves plain HTTP.
+type Config struct {
+	IdempotencyWindow time.Duration
+	RedisAddr         string
+	RedisKey          string
+	WebhookURLs       []string
+	TLS               TLSConfig
+}
+
+// RegisterFlags adds the flags that set c to flags.
+func (c *Config) RegisterFlags(flags *flag.FlagSet) {
+	c.RegisterStoreFlags(flags)
+	flags.DurationVar(&c.IdempotencyWindow, "idempotency-window", poker.DefaultIdempotencyWindow, "how long Idempotency-Key headers are remembered")
+	flags.Var((*urls)(&c.WebhookURLs), "webhook", "URL to post win and leader-changed events to, signed with $"+WebhookSecretEnv+" which must be set; repeat for more than one")
+	c.TLS.RegisterFlags(flags)
+}
+
+// RegisterStoreFlags adds the flags that say where the league is kept to flags, for commands
+// that open the league without serving it.
+func (c *Config) RegisterStoreFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.RedisAddr, "redis", "", "address of a Redis server to share the league through, instead of "+DBFileName)
+	flags.StringVar(&c.RedisKey, "redis-key", poker.DefaultRedisLeagueKey, "Redis key the league is kept in")
+}
+
+// urls is a flag that can be given more than once.
+type urls []string
+
+func (u *urls) String() string {
+	return strings.Join(*u, ",")
+}
+
+func (u *urls) Set(url string) error {
+	*u = append(*u, url)
+	return nil
+}
+
+// OpenFileStore opens the league database, decrypting it with the key from the environment.
+// Every change made to it is audited, on behalf of CommandOrigin unless the context of the
+// change says otherwise.
+func OpenFileStore(options ...poker.FileSystemPlayerStoreOption) (*poker.FileSystemPlayerStore, func(), error) {
+	fileOptions, closeOptions, err := fileStoreOptions()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, closeStore, err := poker.FileSystemFileStoreFromFile(DBFileName, append(options, fileOptions...)...)
+
+	if err != nil {
+		closeOptions()
+		return nil, nil, err
+	}
+
+	return store, func() {
+		closeStore()
+		closeOptions()
+	}, nil
+}
+
+// ReadLeague reads the league from the database, decrypting it with the key from the environment.
+// Unlike OpenFileStore it never creates, migrates or writes the database, or opens the audit log.
+func ReadLeague() (poker.League, error) {
+	key, err := poker.EncryptionKeyFromEnv()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return poker.ReadLeague(DBFileName, poker.WithEncryptionKey(key))
+}
+
+// Restore replaces the league database with the snapshot at backupPath, auditing it as
+// OpenFileStore would. The database does not have to be readable, as it is not opened.
+func Restore(ctx context.Context, backupPath string) error {
+	options, closeOptions, err := fileStoreOptions()
+
+	if err != nil {
+		return err
+	}
+
+	defer closeOptions()
+
+	return poker.Restore(ctx, backupPath, DBFileName, options...)
+}
+
+// fileStoreOptions are the options the league database is opened with: the key from the
+// environment and the audit log, which the returned function closes again.
+func fileStoreOptions() ([]poker.FileSystemPlayerStoreOption, func(), error) {
+	key, err := poker.EncryptionKeyFromEnv()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	auditLog, closeAudit, err := OpenAuditLog()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []poker.FileSystemPlayerStoreOption{
+		poker.WithEncryptionKey(key),
+		poker.WithStoreAuditLog(auditLog, CommandOrigin()),
+	}, closeAudit, nil
+}
+
+// CommandOrigin is who changes made by the poker command itself are audited as.
+func CommandOrigin() poker.Origin {
+	return poker.Origin{Actor: CurrentUser(), Source: poker.AuditSourceCLI}
+}
+
+// CurrentUser names the user running the command.
+func CurrentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+// OpenAuditLog opens the audit log, creating it if it doesn't exist. Its entries are encrypted
+// with the key from the environment, as the database is.
+func OpenAuditLog() (*poker.AuditLog, func(), error) {
+	key, err := poker.EncryptionKeyFromEnv()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return poker.AuditLogFromFile(AuditFileName, poker.WithAuditEncryptionKey(key))
+}
+
+// OpenTournaments opens the tournaments, creating the file if it doesn't exist. It is encrypted
+// with the key from the environment, as the database is.
+func OpenTournaments() (*poker.Tournaments, func(), error) {
+	key, err := poker.EncryptionKeyFromEnv()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return poker.TournamentsFromFile(TournamentsFileName, poker.WithTournamentsEncryptionKey(key))
+}
+
+// Rekey encrypts the tournaments, the audit log and then the league database with key, or
+// stores them all unencrypted if key is nil. The rekey is audited with the new key.
+func Rekey(ctx context.Context, key []byte) error {
+	oldKey, err := poker.EncryptionKeyFromEnv()
+
+	if err != nil {
+		return err
+	}
+
+	auditLog, closeAudit, err := OpenAuditLog()
+
+	if err != nil {
+		return err
+	}
+
+	defer closeAudit()
+
+	store, closeStore, err := poker.FileSystemFileStoreFromFile(DBFileName,
+		poker.WithEncryptionKey(oldKey),
+		poker.WithStoreAuditLog(auditLog, CommandOrigin()),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer closeStore()
+
+	tournaments, closeTournaments, err := OpenTournaments()
+
+	if err != nil {
+		return err
+	}
+
+	defer closeTournaments()
+
+	if err := tournaments.Rekey(key); err != nil {
+		return err
+	}
+
+	if err := auditLog.Rekey(key); err != nil {
+		return err
+	}
+
+	return store.Rekey(ctx, key)
+}
+
+// ReadLeague reads the league from Redis if it is configured, otherwise from the database as
+// ReadLeague does. Nothing is written, not even to the audit log.
+func (c Config) ReadLeague(ctx context.Context) (poker.League, error) {
+	if c.RedisAddr == "" {
+		return ReadLeague()
+	}
+
+	store := poker.NewRedisPlayerStore(c.RedisAddr, c.redisKey())
+	defer store.Close()
+
+	return store.GetLeague(ctx)
+}
+
+// OpenStore opens the Redis store if one is configured, otherwise the league database. Either
+// audits the changes made to it.
+func (c Config) OpenStore() (poker.PlayerStore, func(), error) {
+	if c.RedisAddr != "" {
+		auditLog, closeAudit, err := OpenAuditLog()
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		options := []poker.RedisPlayerStoreOption{poker.WithRedisAuditLog(auditLog, CommandOrigin())}
+
+		if c.IdempotencyWindow > 0 {
+			options = append(options, poker.WithRedisIdempotencyWindow(c.IdempotencyWindow))
+		}
+
+		store := poker.NewRedisPlayerStore(c.RedisAddr, c.redisKey(), options...)
+
+		return store, func() {
+			store.Close()
+			closeAudit()
+		}, nil
+	}
+
+	var options []poker.FileSystemPlayerStoreOption
+
+	if c.IdempotencyWindow > 0 {
+		options = append(options, poker.WithIdempotencyWindow(c.IdempotencyWindow))
+	}
+
+	return OpenFileStore(options...)
+}
+
+// redisKey is the key the league is kept in, DefaultRedisLeagueKey unless configured otherwise.
+func (c Config) redisKey() string {
+	if c.RedisKey == "" {
+		return poker.DefaultRedisLeagueKey
+	}
+
+	return c.RedisKey
+}
+
+// NewServer opens the league, audit log and tournaments and starts the webhooks, returning a
+// server for them all and a function that closes them again. Slash commands are served if
+// $POKER_COMMAND_SECRET is set, and HSTS headers as c.TLS says.
+func (c Config) NewServer() (*poker.PlayerServer, func(), error) {
+	var closers closers
+
+	store, close, err := c.OpenStore()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closers.add(close)
+
+	auditLog, close, err := OpenAuditLog()
+
+	if err != nil {
+		closers.close()
+		return nil, nil, err
+	}
+
+	closers.add(close)
+
+	tournaments, close, err := OpenTournaments()
+
+	if err != nil {
+		closers.close()
+		return nil, nil, err
+	}
+
+	closers.add(close)
+
+	options := []poker.PlayerServerOption{poker.WithAuditLog(auditLog), poker.WithTournaments(tournaments)}
+
+	if len(c.WebhookURLs) > 0 {
+		webhooks, close, err := c.openWebhooks()
+
+		if err != nil {
+			closers.close()
+			return nil, nil, err
+		}
+
+		closers.add(close)
+		options = append(options, poker.WithWebhooks(webhooks))
+	}
+
+	if secret := os.Getenv(CommandSecretEnv); secret != "" {
+		options = append(options, poker.WithCommands([]byte(secret)))
+	}
+
+	if maxAge := c.TLS.HSTS(); maxAge > 0 {
+		options = append(options, poker.WithHSTS(maxAge))
+	}
+
+	return poker.NewPlayerServer(store, options...), closers.close, nil
+}
+
+// openWebhooks starts delivering events to the webhook URLs, keeping those it gives up on in the dead-letter file.
+func (c Config) openWebhooks() (*poker.Webhooks, func(), error) {
+	deadLetters, err := os.OpenFile(DeadLettersFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %s, %v", DeadLettersFileName, err)
+	}
+
+	if key, _ := poker.EncryptionKeyFromEnv(); key != nil {
+		log.Printf("%s is not encrypted, events that cannot be delivered are kept there in plain text", DeadLettersFileName)
+	}
+
+	webhooks, err := poker.NewWebhooks(c.WebhookURLs, []byte(os.Getenv(WebhookSecretEnv)), poker.WithDeadLetterFile(deadLetters))
+
+	if err != nil {
+		deadLetters.Close()
+		return nil, nil, fmt.Errorf("%v, set %s", err, WebhookSecretEnv)
+	}
+
+	return webhooks, func() {
+		webhooks.Close()
+		deadLetters.Close()
+	}, nil
+}
+
+// closers closes what was opened in reverse order.
+type closers []func()
+
+func (c *closers) add(close func()) {
+	*c = append(*c, close)
+}
+
+func (c *closers) close() {
+	for i := len(*c) - 1; i >= 0; i-- {
+		(*c)[i]()
+	}
+}
@@ -0,0 +1,263 @@
+package bootstrap
+
+import (
+	"bytes"
+	poker "command-line-and-project-structure"
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// inTempDir runs the test in a directory of its own, as the files live in the working directory.
+func inTempDir(t *testing.T) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("reads its flags", func(t *testing.T) {
+		var config Config
+		flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+		config.RegisterFlags(flags)
+
+		err := flags.Parse([]string{"-idempotency-window", "1h", "-webhook", "http://a/hook", "-webhook", "http://b/hook"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := Config{
+			IdempotencyWindow: time.Hour,
+			RedisKey:          poker.DefaultRedisLeagueKey,
+			WebhookURLs:       []string{"http://a/hook", "http://b/hook"},
+		}
+
+		if !reflect.DeepEqual(config, want) {
+			t.Errorf("got %+v want %+v", config, want)
+		}
+	})
+
+	t.Run("keeps the league in the database file by default", func(t *testing.T) {
+		inTempDir(t)
+
+		store, close, err := Config{}.OpenStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := store.RecordWin(context.Background(), "Chris"); err != nil {
+			t.Fatal(err)
+		}
+
+		close()
+
+		reopened, close, err := OpenFileStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer close()
+
+		if score, _ := reopened.GetPlayerScore(context.Background(), "Chris"); score != 1 {
+			t.Errorf("got score %d want 1", score)
+		}
+	})
+
+	t.Run("audits changes on behalf of the user running the command", func(t *testing.T) {
+		inTempDir(t)
+
+		store, close, err := OpenFileStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer close()
+
+		if err := store.RecordWin(context.Background(), "Chris"); err != nil {
+			t.Fatal(err)
+		}
+
+		auditLog, closeAudit, err := OpenAuditLog()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer closeAudit()
+
+		entries, err := auditLog.Entries("Chris")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 || entries[0].Actor != CurrentUser() || entries[0].Source != poker.AuditSourceCLI {
+			t.Errorf("unexpected audit entries %+v", entries)
+		}
+	})
+}
+
+func TestNewServer(t *testing.T) {
+	t.Run("serves slash commands only with a signing secret", func(t *testing.T) {
+		inTempDir(t)
+
+		for _, c := range []struct {
+			secret string
+			want   int
+		}{
+			{"", http.StatusNotFound},
+			{"s3cret", http.StatusUnauthorized},
+		} {
+			t.Setenv(CommandSecretEnv, c.secret)
+
+			server, close, err := Config{}.NewServer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/commands", nil))
+			close()
+
+			if response.Code != c.want {
+				t.Errorf("with secret %q got status %d want %d", c.secret, response.Code, c.want)
+			}
+		}
+	})
+
+	t.Run("opens the dead-letter file for webhooks", func(t *testing.T) {
+		inTempDir(t)
+		t.Setenv(WebhookSecretEnv, "secret")
+
+		_, close, err := Config{WebhookURLs: []string{"http://127.0.0.1:0/hook"}}.NewServer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer close()
+
+		if _, err := os.Stat(DeadLettersFileName); err != nil {
+			t.Errorf("dead-letter file was not created, %v", err)
+		}
+	})
+
+	t.Run("refuses webhooks without a secret", func(t *testing.T) {
+		inTempDir(t)
+		t.Setenv(WebhookSecretEnv, "")
+
+		_, _, err := Config{WebhookURLs: []string{"http://127.0.0.1:0/hook"}}.NewServer()
+
+		if err == nil || !strings.Contains(err.Error(), WebhookSecretEnv) {
+			t.Errorf("got %v want an error asking for %s", err, WebhookSecretEnv)
+		}
+	})
+}
+
+func TestTLSConfig(t *testing.T) {
+	t.Run("rejects flags that don't go together", func(t *testing.T) {
+		for _, c := range []TLSConfig{
+			{CertFile: "cert.pem"},
+			{KeyFile: "key.pem"},
+			{SelfSigned: true, CertFile: "cert.pem", KeyFile: "key.pem"},
+			{RedirectAddr: ":80"},
+		} {
+			if err := c.Validate(); err == nil {
+				t.Errorf("expected an error for %+v", c)
+			}
+		}
+	})
+
+	t.Run("keeps the self-signed certificate in the working directory", func(t *testing.T) {
+		inTempDir(t)
+
+		config := TLSConfig{SelfSigned: true}
+
+		first, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, name := range []string{CertFileName, KeyFileName} {
+			if _, err := os.Stat(name); err != nil {
+				t.Errorf("%s was not created, %v", name, err)
+			}
+		}
+
+		second, err := config.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(first.Certificates[0].Certificate[0], second.Certificates[0].Certificate[0]) {
+			t.Error("self-signed certificate was generated again")
+		}
+	})
+
+	t.Run("serves HSTS headers over HTTPS", func(t *testing.T) {
+		hour := time.Hour
+
+		for _, c := range []struct {
+			tls  TLSConfig
+			want string
+		}{
+			{TLSConfig{SelfSigned: true, HSTSMaxAge: &hour}, "max-age=3600"},
+			{TLSConfig{SelfSigned: true}, ""},
+		} {
+			inTempDir(t)
+
+			handler, close, err := Config{TLS: c.tls}.NewServer()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			server := httptest.NewTLSServer(handler)
+
+			response, err := server.Client().Get(server.URL + "/league")
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			server.Close()
+			close()
+
+			if got := response.Header.Get("Strict-Transport-Security"); got != c.want {
+				t.Errorf("with %+v got Strict-Transport-Security %q want %q", c.tls, got, c.want)
+			}
+		}
+	})
+
+	t.Run("only tells browsers to keep to HTTPS by default with the operator's certificate", func(t *testing.T) {
+		for _, c := range []struct {
+			args []string
+			want time.Duration
+		}{
+			{nil, 0},
+			{[]string{"-hsts", "1h"}, 0},
+			{[]string{"-tls-cert", "cert.pem", "-tls-key", "key.pem"}, poker.DefaultHSTSMaxAge},
+			{[]string{"-tls-cert", "cert.pem", "-tls-key", "key.pem", "-hsts", "0"}, 0},
+			{[]string{"-tls-self-signed"}, 0},
+			{[]string{"-tls-self-signed", "-hsts", "1h"}, time.Hour},
+		} {
+			var config TLSConfig
+			flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+			config.RegisterFlags(flags)
+
+			if err := flags.Parse(c.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := config.HSTS(); got != c.want {
+				t.Errorf("with %q got HSTS max age %v want %v", c.args, got, c.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package bootstrap
+
+import (
+	poker "command-line-and-project-structure"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Files the self-signed certificate is kept in, so browsers only have to be told to trust it once.
+const (
+	CertFileName = "game.cert.pem"
+	KeyFileName  = "game.key.pem"
+)
+
+// selfSignedHosts are the hosts a generated certificate is valid for, besides the machine's own name.
+var selfSignedHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// TLSConfig says whether the server speaks HTTPS and with which certificate. The zero value
+// serves plain HTTP. A nil HSTSMaxAge sends HSTS headers for poker.DefaultHSTSMaxAge with the
+// operator's certificate but none with a self-signed one, which would pin HTTPS on localhost.
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	SelfSigned   bool
+	RedirectAddr string
+	HSTSMaxAge   *time.Duration
+}
+
+// RegisterFlags adds the flags that set c to flags.
+func (c *TLSConfig) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.CertFile, "tls-cert", "", "PEM certificate to serve HTTPS with, used with -tls-key")
+	flags.StringVar(&c.KeyFile, "tls-key", "", "PEM private key of -tls-cert")
+	flags.BoolVar(&c.SelfSigned, "tls-self-signed", false, "serve HTTPS with a self-signed certificate for local use, kept in "+CertFileName+" and "+KeyFileName)
+	flags.StringVar(&c.RedirectAddr, "redirect-http", "", "address to also listen on for plain HTTP, redirecting to HTTPS")
+	flags.Var(optionalDuration{&c.HSTSMaxAge}, "hsts", "`duration` browsers are told to only use HTTPS for, 0 to not tell them (default "+poker.DefaultHSTSMaxAge.String()+" with -tls-cert, 0 with -tls-self-signed)")
+}
+
+// HSTS returns how long browsers are told to only use HTTPS, 0 if they aren't told.
+func (c TLSConfig) HSTS() time.Duration {
+	switch {
+	case !c.Enabled():
+		return 0
+	case c.HSTSMaxAge != nil:
+		return *c.HSTSMaxAge
+	case c.SelfSigned:
+		return 0
+	}
+
+	return poker.DefaultHSTSMaxAge
+}
+
+// optionalDuration is a duration flag that is left nil unless it is given.
+type optionalDuration struct {
+	d **time.Duration
+}
+
+func (o optionalDuration) String() string {
+	if o.d == nil || *o.d == nil {
+		return ""
+	}
+
+	return (**o.d).String()
+}
+
+func (o optionalDuration) Set(s string) error {
+	d, err := time.ParseDuration(s)
+
+	if err != nil {
+		return err
+	}
+
+	*o.d = &d
+	return nil
+}
+
+// Enabled reports whether the server speaks HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.SelfSigned
+}
+
+// Validate reports flags that don't make sense together.
+func (c TLSConfig) Validate() error {
+	switch {
+	case c.SelfSigned && (c.CertFile != "" || c.KeyFile != ""):
+		return errors.New("-tls-self-signed cannot be used with -tls-cert or -tls-key")
+	case (c.CertFile == "") != (c.KeyFile == ""):
+		return errors.New("-tls-cert and -tls-key must be given together")
+	case c.RedirectAddr != "" && !c.Enabled():
+		return errors.New("-redirect-http needs HTTPS, set -tls-cert and -tls-key or -tls-self-signed")
+	}
+
+	return nil
+}
+
+// Load returns the TLS configuration to serve with, generating the self-signed certificate
+// if it is asked for and doesn't exist yet.
+func (c TLSConfig) Load() (*tls.Config, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var cert tls.Certificate
+	var err error
+
+	if c.SelfSigned {
+		hosts := selfSignedHosts
+
+		if hostname, err := os.Hostname(); err == nil && hostname != "" {
+			hosts = append([]string{hostname}, hosts...)
+		}
+
+		cert, err = poker.SelfSignedCertificateFromFiles(CertFileName, KeyFileName, hosts)
+	} else {
+		cert, err = tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+
+		if err != nil {
+			err = fmt.Errorf("problem loading certificate from %s and %s, %v", c.CertFile, c.KeyFile, err)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
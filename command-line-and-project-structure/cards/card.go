@@ -0,0 +1,115 @@
+// Package cards deals playing cards and ranks poker hands.
+package cards
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Suit is one of the four suits of a deck.
+type Suit uint8
+
+const (
+	Clubs Suit = iota
+	Diamonds
+	Hearts
+	Spades
+)
+
+// Suits lists every suit, lowest first.
+var Suits = []Suit{Clubs, Diamonds, Hearts, Spades}
+
+const suitSymbols = "cdhs"
+
+var suitNames = [...]string{"Clubs", "Diamonds", "Hearts", "Spades"}
+
+func (s Suit) String() string {
+	return suitNames[s]
+}
+
+// Rank is the value of a card, from Two up to Ace.
+type Rank uint8
+
+const (
+	Two Rank = iota + 2
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+)
+
+// Ranks lists every rank, lowest first.
+var Ranks = []Rank{Two, Three, Four, Five, Six, Seven, Eight, Nine, Ten, Jack, Queen, King, Ace}
+
+const rankSymbols = "23456789TJQKA"
+
+var rankNames = [...]string{"Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten", "Jack", "Queen", "King", "Ace"}
+
+func (r Rank) String() string {
+	return rankNames[r-Two]
+}
+
+// plural names several cards of the rank, as in "Full House, Kings over Sixes".
+func (r Rank) plural() string {
+	if r == Six {
+		return "Sixes"
+	}
+
+	return r.String() + "s"
+}
+
+// Card is a playing card.
+type Card struct {
+	Rank Rank
+	Suit Suit
+}
+
+// String writes the card as its rank and suit symbols, such as "Ah" or "Td".
+func (c Card) String() string {
+	return string([]byte{rankSymbols[c.Rank-Two], suitSymbols[c.Suit]})
+}
+
+// ParseCard reads a card written as by Card.String, such as "Ah" or "Td".
+func ParseCard(s string) (Card, error) {
+	if len(s) != 2 {
+		return Card{}, fmt.Errorf("card %q should be a rank and a suit, like Ah", s)
+	}
+
+	rank := strings.IndexByte(rankSymbols, strings.ToUpper(s[:1])[0])
+	suit := strings.IndexByte(suitSymbols, strings.ToLower(s[1:])[0])
+
+	if rank < 0 {
+		return Card{}, fmt.Errorf("card %q has unknown rank %q", s, s[:1])
+	}
+
+	if suit < 0 {
+		return Card{}, fmt.Errorf("card %q has unknown suit %q", s, s[1:])
+	}
+
+	return Card{Rank(rank) + Two, Suit(suit)}, nil
+}
+
+// ParseCards reads cards separated by spaces, such as "Ah Kd 7c".
+func ParseCards(s string) ([]Card, error) {
+	var cards []Card
+
+	for _, field := range strings.Fields(s) {
+		card, err := ParseCard(field)
+
+		if err != nil {
+			return nil, err
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
@@ -0,0 +1,84 @@
+package cards
+
+import (
+	"fmt"
+	"testing"
+	"testing/quick"
+)
+
+func TestParseCard(t *testing.T) {
+	cases := []struct {
+		Text string
+		Card Card
+	}{
+		{"2c", Card{Two, Clubs}},
+		{"Td", Card{Ten, Diamonds}},
+		{"Qh", Card{Queen, Hearts}},
+		{"As", Card{Ace, Spades}},
+		{"ks", Card{King, Spades}},
+		{"JH", Card{Jack, Hearts}},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q gets parsed to %v of %v", c.Text, c.Card.Rank, c.Card.Suit), func(t *testing.T) {
+			got, err := ParseCard(c.Text)
+
+			assertNoError(t, err)
+
+			if got != c.Card {
+				t.Errorf("got %v want %v", got, c.Card)
+			}
+		})
+	}
+
+	for _, text := range []string{"", "A", "10h", "1h", "Ax"} {
+		t.Run(fmt.Sprintf("%q is not a card", text), func(t *testing.T) {
+			if _, err := ParseCard(text); err == nil {
+				t.Error("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func TestPropertiesOfCards(t *testing.T) {
+	assertion := func(rank, suit uint8) bool {
+		card := Card{Ranks[int(rank)%len(Ranks)], Suits[int(suit)%len(Suits)]}
+		parsed, err := ParseCard(card.String())
+		return err == nil && parsed == card
+	}
+
+	if err := quick.Check(assertion, nil); err != nil {
+		t.Error("failed checks", err)
+	}
+}
+
+func TestParseCards(t *testing.T) {
+	got, err := ParseCards("Ah  Kd\t7c")
+
+	assertNoError(t, err)
+	assertCards(t, got, []Card{{Ace, Hearts}, {King, Diamonds}, {Seven, Clubs}})
+}
+
+func mustParseCards(t testing.TB, s string) []Card {
+	t.Helper()
+
+	cards, err := ParseCards(s)
+	assertNoError(t, err)
+
+	return cards
+}
+
+func assertCards(t testing.TB, got, want []Card) {
+	t.Helper()
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("didn't expect an error but got one, %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+var (
+	// ErrNotEnoughCards is returned when a deal needs more cards than are left in the deck.
+	ErrNotEnoughCards = errors.New("not enough cards left in the deck")
+
+	// ErrNegativeDeal is returned when asked to deal fewer than no cards or players.
+	ErrNegativeDeal = errors.New("cannot deal a negative number of cards or players")
+)
+
+// Deck is a pile of cards dealt from the top.
+type Deck struct {
+	cards []Card
+}
+
+// NewDeck returns the 52 cards in order, Two of Clubs on top.
+func NewDeck() *Deck {
+	d := &Deck{}
+
+	for _, suit := range Suits {
+		for _, rank := range Ranks {
+			d.cards = append(d.cards, Card{rank, suit})
+		}
+	}
+
+	return d
+}
+
+// NewShuffledDeck returns a full deck shuffled by seed, so the same seed always deals the same game.
+func NewShuffledDeck(seed int64) *Deck {
+	d := NewDeck()
+	d.Shuffle(rand.New(rand.NewSource(seed)))
+	return d
+}
+
+// Shuffle puts the cards left in the deck in a random order drawn from rng.
+func (d *Deck) Shuffle(rng *rand.Rand) {
+	rng.Shuffle(len(d.cards), func(i, j int) {
+		d.cards[i], d.cards[j] = d.cards[j], d.cards[i]
+	})
+}
+
+// Len returns how many cards are left in the deck.
+func (d *Deck) Len() int {
+	return len(d.cards)
+}
+
+// Deal takes n cards from the top of the deck.
+func (d *Deck) Deal(n int) ([]Card, error) {
+	if n < 0 {
+		return nil, ErrNegativeDeal
+	}
+
+	if n > len(d.cards) {
+		return nil, ErrNotEnoughCards
+	}
+
+	dealt := append([]Card{}, d.cards[:n]...)
+	d.cards = d.cards[n:]
+
+	return dealt, nil
+}
+
+// HoldemDeal is a game of Texas Hold'em dealt to the river.
+type HoldemDeal struct {
+	Hands [][]Card
+	Flop  []Card
+	Turn  Card
+	River Card
+}
+
+// Board returns the five community cards.
+func (h HoldemDeal) Board() []Card {
+	return append(append([]Card{}, h.Flop...), h.Turn, h.River)
+}
+
+// DealHoldem deals two hole cards to each player one at a time, then the flop, turn
+// and river, burning a card before each.
+func (d *Deck) DealHoldem(players int) (HoldemDeal, error) {
+	if players < 0 {
+		return HoldemDeal{}, ErrNegativeDeal
+	}
+
+	if players*2+8 > len(d.cards) {
+		return HoldemDeal{}, ErrNotEnoughCards
+	}
+
+	deal := HoldemDeal{Hands: d.dealHands(players, 2)}
+
+	d.Deal(1)
+	deal.Flop, _ = d.Deal(3)
+	d.Deal(1)
+	turn, _ := d.Deal(1)
+	d.Deal(1)
+	river, _ := d.Deal(1)
+
+	deal.Turn, deal.River = turn[0], river[0]
+
+	return deal, nil
+}
+
+// DealFiveCardDraw deals five cards to each player one at a time.
+func (d *Deck) DealFiveCardDraw(players int) ([][]Card, error) {
+	if players < 0 {
+		return nil, ErrNegativeDeal
+	}
+
+	if players*5 > len(d.cards) {
+		return nil, ErrNotEnoughCards
+	}
+
+	return d.dealHands(players, 5), nil
+}
+
+// Draw replaces the cards of hand at the discarded positions with cards from the deck,
+// as in the draw of Five-Card Draw.
+func (d *Deck) Draw(hand []Card, discards ...int) ([]Card, error) {
+	discarded := map[int]bool{}
+
+	for _, position := range discards {
+		if position < 0 || position >= len(hand) || discarded[position] {
+			return nil, fmt.Errorf("cannot discard card %d of a hand of %d", position, len(hand))
+		}
+
+		discarded[position] = true
+	}
+
+	replacements, err := d.Deal(len(discards))
+
+	if err != nil {
+		return nil, err
+	}
+
+	drawn := append([]Card{}, hand...)
+
+	for i, position := range discards {
+		drawn[position] = replacements[i]
+	}
+
+	return drawn, nil
+}
+
+// dealHands deals size cards to each of players, going round the table one card at a time.
+func (d *Deck) dealHands(players, size int) [][]Card {
+	hands := make([][]Card, players)
+
+	for i := 0; i < size; i++ {
+		for p := range hands {
+			card, _ := d.Deal(1)
+			hands[p] = append(hands[p], card[0])
+		}
+	}
+
+	return hands
+}
@@ -0,0 +1,206 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"testing/quick"
+)
+
+func TestDeck(t *testing.T) {
+	t.Run("a new deck has every card once", func(t *testing.T) {
+		assertFullDeck(t, NewDeck())
+	})
+
+	t.Run("shuffling with the same seed deals the same cards", func(t *testing.T) {
+		first, _ := NewShuffledDeck(42).Deal(52)
+		second, _ := NewShuffledDeck(42).Deal(52)
+		other, _ := NewShuffledDeck(43).Deal(52)
+
+		assertCards(t, first, second)
+
+		if fmt.Sprint(first) == fmt.Sprint(other) {
+			t.Error("expected different seeds to shuffle differently")
+		}
+	})
+
+	t.Run("deals from the top", func(t *testing.T) {
+		deck := NewDeck()
+
+		dealt, err := deck.Deal(3)
+
+		assertNoError(t, err)
+		assertCards(t, dealt, mustParseCards(t, "2c 3c 4c"))
+
+		if deck.Len() != 49 {
+			t.Errorf("got %d cards left want %d", deck.Len(), 49)
+		}
+	})
+
+	t.Run("refuses to deal more cards than are left", func(t *testing.T) {
+		deck := NewDeck()
+		deck.Deal(50)
+
+		_, err := deck.Deal(3)
+
+		if !errors.Is(err, ErrNotEnoughCards) {
+			t.Errorf("got %v want %v", err, ErrNotEnoughCards)
+		}
+
+		if deck.Len() != 2 {
+			t.Errorf("got %d cards left want %d", deck.Len(), 2)
+		}
+	})
+
+	negative := []struct {
+		what string
+		deal func(*Deck) error
+	}{
+		{"cards", func(d *Deck) error { _, err := d.Deal(-1); return err }},
+		{"Hold'em hands", func(d *Deck) error { _, err := d.DealHoldem(-1); return err }},
+		{"Five-Card Draw hands", func(d *Deck) error { _, err := d.DealFiveCardDraw(-1); return err }},
+	}
+
+	for _, c := range negative {
+		t.Run("refuses to deal a negative number of "+c.what, func(t *testing.T) {
+			deck := NewDeck()
+
+			if err := c.deal(deck); !errors.Is(err, ErrNegativeDeal) {
+				t.Errorf("got %v want %v", err, ErrNegativeDeal)
+			}
+
+			if deck.Len() != 52 {
+				t.Errorf("got %d cards left want %d", deck.Len(), 52)
+			}
+		})
+	}
+}
+
+func TestDealHoldem(t *testing.T) {
+	t.Run("deals hole cards round the table then burns before each street", func(t *testing.T) {
+		deck := NewDeck()
+
+		deal, err := deck.DealHoldem(2)
+
+		assertNoError(t, err)
+		assertCards(t, deal.Hands[0], mustParseCards(t, "2c 4c"))
+		assertCards(t, deal.Hands[1], mustParseCards(t, "3c 5c"))
+		assertCards(t, deal.Flop, mustParseCards(t, "7c 8c 9c"))
+		assertCards(t, deal.Board(), mustParseCards(t, "7c 8c 9c Jc Kc"))
+	})
+
+	t.Run("refuses more players than the deck can deal to", func(t *testing.T) {
+		if _, err := NewDeck().DealHoldem(23); !errors.Is(err, ErrNotEnoughCards) {
+			t.Errorf("got %v want %v", err, ErrNotEnoughCards)
+		}
+	})
+}
+
+func TestPropertiesOfDealing(t *testing.T) {
+	t.Run("Hold'em never deals a card twice", func(t *testing.T) {
+		assertion := func(seed int64, players uint8) bool {
+			n := int(players)%22 + 1
+			deck := NewShuffledDeck(seed)
+
+			deal, err := deck.DealHoldem(n)
+
+			if err != nil || deck.Len() != 52-2*n-8 {
+				return false
+			}
+
+			cards := deal.Board()
+			for _, hand := range deal.Hands {
+				cards = append(cards, hand...)
+			}
+
+			return len(cards) == 2*n+5 && unique(cards)
+		}
+
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Error("failed checks", err)
+		}
+	})
+
+	t.Run("Five-Card Draw never deals a card twice", func(t *testing.T) {
+		assertion := func(seed int64, players uint8, discards uint8) bool {
+			n := int(players)%6 + 1
+			deck := NewShuffledDeck(seed)
+
+			hands, err := deck.DealFiveCardDraw(n)
+
+			if err != nil {
+				return false
+			}
+
+			var positions []int
+			for i := 0; i < 5; i++ {
+				if discards&(1<<i) != 0 {
+					positions = append(positions, i)
+				}
+			}
+
+			hands[0], err = deck.Draw(hands[0], positions...)
+
+			var cards []Card
+			for _, hand := range hands {
+				cards = append(cards, hand...)
+			}
+
+			return err == nil && len(cards) == 5*n && unique(cards) && deck.Len() == 52-5*n-len(positions)
+		}
+
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Error("failed checks", err)
+		}
+	})
+}
+
+func TestDraw(t *testing.T) {
+	t.Run("replaces the discarded cards", func(t *testing.T) {
+		deck := NewDeck()
+		hand := mustParseCards(t, "Ah Kh Qh 2s 3d")
+
+		drawn, err := deck.Draw(hand, 3, 4)
+
+		assertNoError(t, err)
+		assertCards(t, drawn, mustParseCards(t, "Ah Kh Qh 2c 3c"))
+	})
+
+	for _, discards := range [][]int{{5}, {-1}, {1, 1}} {
+		t.Run("refuses to discard a card the hand does not hold", func(t *testing.T) {
+			deck := NewDeck()
+
+			if _, err := deck.Draw(mustParseCards(t, "Ah Kh Qh 2s 3d"), discards...); err == nil {
+				t.Errorf("expected an error discarding %v but didn't get one", discards)
+			}
+
+			if deck.Len() != 52 {
+				t.Errorf("expected no cards to be dealt but %d were", 52-deck.Len())
+			}
+		})
+	}
+}
+
+func assertFullDeck(t testing.TB, deck *Deck) {
+	t.Helper()
+
+	cards, err := deck.Deal(52)
+	assertNoError(t, err)
+
+	if !unique(cards) || deck.Len() != 0 {
+		t.Errorf("expected 52 different cards but got %v", cards)
+	}
+}
+
+func unique(cards []Card) bool {
+	seen := map[Card]bool{}
+
+	for _, c := range cards {
+		if seen[c] {
+			return false
+		}
+		seen[c] = true
+	}
+
+	return true
+}
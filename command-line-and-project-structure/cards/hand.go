@@ -0,0 +1,208 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Category is the kind of a poker hand, from HighCard up to StraightFlush.
+type Category uint8
+
+const (
+	HighCard Category = iota
+	OnePair
+	TwoPair
+	ThreeOfAKind
+	Straight
+	Flush
+	FullHouse
+	FourOfAKind
+	StraightFlush
+)
+
+var categoryNames = [...]string{"High Card", "Pair", "Two Pair", "Three of a Kind", "Straight", "Flush", "Full House", "Four of a Kind", "Straight Flush"}
+
+func (c Category) String() string {
+	return categoryNames[c]
+}
+
+// HandRank is the value of the best five-card poker hand among some cards.
+// A better hand has a greater HandRank and hands of equal value have equal ranks,
+// so ranks can be compared directly to find a winner.
+type HandRank uint32
+
+// A HandRank keeps the category above the ranks that decide between hands of that
+// category, most significant first, four bits each.
+const (
+	rankBits      = 4
+	categoryShift = 5 * rankBits
+)
+
+func newHandRank(category Category, ranks ...Rank) HandRank {
+	h := HandRank(category) << categoryShift
+
+	for i, r := range ranks {
+		h |= HandRank(r) << (rankBits * (4 - i))
+	}
+
+	return h
+}
+
+// Category returns the kind of hand.
+func (h HandRank) Category() Category {
+	return Category(h >> categoryShift)
+}
+
+// rank returns the i-th most significant rank deciding the hand.
+func (h HandRank) rank(i int) Rank {
+	return Rank(h>>(rankBits*(4-i))) & 0xf
+}
+
+// String names the hand, such as "Full House, Kings over Sevens" or "Pair of Queens".
+func (h HandRank) String() string {
+	first, second := h.rank(0), h.rank(1)
+
+	switch h.Category() {
+	case StraightFlush:
+		if first == Ace {
+			return "Royal Flush"
+		}
+		return fmt.Sprintf("Straight Flush, %s high", first)
+	case FourOfAKind:
+		return fmt.Sprintf("Four of a Kind, %s", first.plural())
+	case FullHouse:
+		return fmt.Sprintf("Full House, %s over %s", first.plural(), second.plural())
+	case Flush:
+		return fmt.Sprintf("Flush, %s high", first)
+	case Straight:
+		return fmt.Sprintf("Straight, %s high", first)
+	case ThreeOfAKind:
+		return fmt.Sprintf("Three of a Kind, %s", first.plural())
+	case TwoPair:
+		return fmt.Sprintf("Two Pair, %s and %s", first.plural(), second.plural())
+	case OnePair:
+		return fmt.Sprintf("Pair of %s", first.plural())
+	default:
+		return fmt.Sprintf("High Card, %s", first)
+	}
+}
+
+var (
+	// ErrHandSize is returned when evaluating fewer than five or more than seven cards.
+	ErrHandSize = errors.New("a hand is evaluated from five to seven cards")
+
+	// ErrDuplicateCard is returned when evaluating cards that could not come from one deck.
+	ErrDuplicateCard = errors.New("a hand cannot hold the same card twice")
+)
+
+// Evaluate returns the rank of the best five-card hand among five to seven cards,
+// such as a Hold'em player's hole cards with the board.
+func Evaluate(cards []Card) (HandRank, error) {
+	if len(cards) < 5 || len(cards) > 7 {
+		return 0, ErrHandSize
+	}
+
+	var counts [Ace + 1]int
+	var bySuit [4]uint16
+	var all uint16
+
+	for _, c := range cards {
+		bit := uint16(1) << c.Rank
+
+		if bySuit[c.Suit]&bit != 0 {
+			return 0, ErrDuplicateCard
+		}
+
+		counts[c.Rank]++
+		bySuit[c.Suit] |= bit
+		all |= bit
+	}
+
+	// With at most seven cards only one suit can make a flush.
+	for _, suited := range bySuit {
+		if bits.OnesCount16(suited) < 5 {
+			continue
+		}
+
+		if high := straightHigh(suited); high != 0 {
+			return newHandRank(StraightFlush, high), nil
+		}
+
+		return newHandRank(Flush, highest(suited, 5)...), nil
+	}
+
+	var quads, trips, pairs []Rank
+
+	for r := Ace; r >= Two; r-- {
+		switch counts[r] {
+		case 4:
+			quads = append(quads, r)
+		case 3:
+			trips = append(trips, r)
+		case 2:
+			pairs = append(pairs, r)
+		}
+	}
+
+	without := func(ranks ...Rank) uint16 {
+		mask := all
+		for _, r := range ranks {
+			mask &^= 1 << r
+		}
+		return mask
+	}
+
+	switch {
+	case len(quads) > 0:
+		return newHandRank(FourOfAKind, quads[0], highest(without(quads[0]), 1)[0]), nil
+	case len(trips) > 1:
+		return newHandRank(FullHouse, trips[0], trips[1]), nil
+	case len(trips) > 0 && len(pairs) > 0:
+		return newHandRank(FullHouse, trips[0], pairs[0]), nil
+	}
+
+	if high := straightHigh(all); high != 0 {
+		return newHandRank(Straight, high), nil
+	}
+
+	switch {
+	case len(trips) > 0:
+		return newHandRank(ThreeOfAKind, append([]Rank{trips[0]}, highest(without(trips[0]), 2)...)...), nil
+	case len(pairs) > 1:
+		return newHandRank(TwoPair, pairs[0], pairs[1], highest(without(pairs[0], pairs[1]), 1)[0]), nil
+	case len(pairs) > 0:
+		return newHandRank(OnePair, append([]Rank{pairs[0]}, highest(without(pairs[0]), 3)...)...), nil
+	default:
+		return newHandRank(HighCard, highest(all, 5)...), nil
+	}
+}
+
+// straightHigh returns the top card of the highest straight among ranks, or 0 if there is none.
+// An Ace also counts low, below Two, to make a Five high straight.
+func straightHigh(ranks uint16) Rank {
+	if ranks&(1<<Ace) != 0 {
+		ranks |= 1 << (Two - 1)
+	}
+
+	for high := Ace; high >= Five; high-- {
+		if ranks>>(high-4)&0x1f == 0x1f {
+			return high
+		}
+	}
+
+	return 0
+}
+
+// highest returns the n highest ranks among ranks, highest first.
+func highest(ranks uint16, n int) []Rank {
+	var top []Rank
+
+	for r := Ace; r >= Two && len(top) < n; r-- {
+		if ranks&(1<<r) != 0 {
+			top = append(top, r)
+		}
+	}
+
+	return top
+}
@@ -0,0 +1,243 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/quick"
+)
+
+var namedHands = []struct {
+	Cards string
+	Name  string
+}{
+	{"Ah Kh Qh Jh Th", "Royal Flush"},
+	{"9c 8c 7c 6c 5c 2d Ad", "Straight Flush, Nine high"},
+	{"5d 4d 3d 2d Ad Kd", "Straight Flush, Five high"},
+	{"9s 9h 9d 9c Kd", "Four of a Kind, Nines"},
+	{"Ks Kh Kd 7c 7d", "Full House, Kings over Sevens"},
+	{"6s 6h 6d 2c 2d 2h", "Full House, Sixes over Twos"},
+	{"Ah Jh 8h 4h 2h Kc Qc", "Flush, Ace high"},
+	{"Ts 9h 8d 7c 6d", "Straight, Ten high"},
+	{"5s 4h 3d 2c Ad", "Straight, Five high"},
+	{"4s 4h 4d Kc 2d", "Three of a Kind, Fours"},
+	{"Js Jh 5d 5c 2d", "Two Pair, Jacks and Fives"},
+	{"Qs Qh 9d 5c 2d", "Pair of Queens"},
+	{"As Jh 9d 5c 2d 3h 7c", "High Card, Ace"},
+}
+
+func TestEvaluateNamesHands(t *testing.T) {
+	for _, h := range namedHands {
+		t.Run(fmt.Sprintf("%q is %s", h.Cards, h.Name), func(t *testing.T) {
+			got := evaluate(t, h.Cards)
+
+			if got.String() != h.Name {
+				t.Errorf("got %q want %q", got, h.Name)
+			}
+		})
+	}
+}
+
+func TestEvaluateOrdersHands(t *testing.T) {
+	cases := []struct {
+		Better, Worse string
+	}{
+		{"Ah Kh Qh Jh Th", "9s 9h 9d 9c Kd"},
+		{"6c 5c 4c 3c 2c", "5d 4d 3d 2d Ad"},
+		{"9s 9h 9d 9c Kd", "9s 9h 9d 9c Qd"},
+		{"Ks Kh Kd 2c 2d", "Qs Qh Qd Ac Ad"},
+		{"Ah Jh 8h 4h 3h", "Ah Jh 8h 4h 2h"},
+		{"6s 5h 4d 3c 2d", "5s 4h 3d 2c Ad"},
+		{"4s 4h 4d Kc 3d", "4s 4h 4d Kc 2d"},
+		{"Js Jh 5d 5c 3d", "Js Jh 5d 5c 2d"},
+		{"Js Jh 6d 6c 2d", "Js Jh 5d 5c Ad"},
+		{"Qs Qh 9d 5c 3d", "Qs Qh 9d 5c 2d"},
+		{"As Jh 9d 5c 3d", "As Jh 9d 5c 2d"},
+		{"2s 2h 3d 4c 5d", "As Kh Qd Jc 9d"},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q beats %q", c.Better, c.Worse), func(t *testing.T) {
+			better, worse := evaluate(t, c.Better), evaluate(t, c.Worse)
+
+			if better <= worse {
+				t.Errorf("expected %s to beat %s", better, worse)
+			}
+		})
+	}
+
+	t.Run("the same hand in other suits ties", func(t *testing.T) {
+		if evaluate(t, "As Jh 9d 5c 3d") != evaluate(t, "Ac Jd 9h 5s 3s") {
+			t.Error("expected the hands to tie")
+		}
+	})
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	cases := []struct {
+		Cards string
+		Err   error
+	}{
+		{"As Kd Qh Jc", ErrHandSize},
+		{"As Kd Qh Jc Ts 9s 8s 7s", ErrHandSize},
+		{"As Kd Qh Jc As", ErrDuplicateCard},
+	}
+
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%q is refused", c.Cards), func(t *testing.T) {
+			_, err := Evaluate(mustParseCards(t, c.Cards))
+
+			if !errors.Is(err, c.Err) {
+				t.Errorf("got %v want %v", err, c.Err)
+			}
+		})
+	}
+}
+
+// TestEvaluateEveryFiveCardHand checks all 2,598,960 five-card hands against how often
+// each category is known to come up, and that they fall into 7,462 distinct ranks.
+func TestEvaluateEveryFiveCardHand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping every five-card hand in short mode")
+	}
+
+	want := map[Category]int{
+		StraightFlush: 40,
+		FourOfAKind:   624,
+		FullHouse:     3744,
+		Flush:         5108,
+		Straight:      10200,
+		ThreeOfAKind:  54912,
+		TwoPair:       123552,
+		OnePair:       1098240,
+		HighCard:      1302540,
+	}
+
+	deck, _ := NewDeck().Deal(52)
+	got := map[Category]int{}
+	ranks := map[HandRank]bool{}
+	hand := make([]Card, 5)
+
+	for a := 0; a < 52; a++ {
+		for b := a + 1; b < 52; b++ {
+			for c := b + 1; c < 52; c++ {
+				for d := c + 1; d < 52; d++ {
+					for e := d + 1; e < 52; e++ {
+						hand[0], hand[1], hand[2], hand[3], hand[4] = deck[a], deck[b], deck[c], deck[d], deck[e]
+
+						rank, err := Evaluate(hand)
+						assertNoError(t, err)
+
+						got[rank.Category()]++
+						ranks[rank] = true
+					}
+				}
+			}
+		}
+	}
+
+	for category, count := range want {
+		if got[category] != count {
+			t.Errorf("got %d hands of %s want %d", got[category], category, count)
+		}
+	}
+
+	if len(ranks) != 7462 {
+		t.Errorf("got %d distinct hand ranks want %d", len(ranks), 7462)
+	}
+}
+
+func TestPropertiesOfEvaluate(t *testing.T) {
+	t.Run("seven cards rank as the best five among them", func(t *testing.T) {
+		assertion := func(seed int64) bool {
+			cards, _ := NewShuffledDeck(seed).Deal(7)
+			got, err := Evaluate(cards)
+
+			return err == nil && got == bestOfFive(t, cards)
+		}
+
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Error("failed checks", err)
+		}
+	})
+
+	t.Run("the order of the cards does not matter", func(t *testing.T) {
+		assertion := func(seed int64) bool {
+			cards, _ := NewShuffledDeck(seed).Deal(7)
+			shuffled := append([]Card{}, cards...)
+			rand.New(rand.NewSource(seed)).Shuffle(len(shuffled), func(i, j int) {
+				shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+			})
+
+			return evaluateCards(t, cards) == evaluateCards(t, shuffled)
+		}
+
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Error("failed checks", err)
+		}
+	})
+
+	t.Run("renaming the suits does not matter", func(t *testing.T) {
+		assertion := func(seed int64, shift uint8) bool {
+			cards, _ := NewShuffledDeck(seed).Deal(7)
+			renamed := make([]Card, len(cards))
+
+			for i, c := range cards {
+				renamed[i] = Card{c.Rank, Suit((int(c.Suit) + int(shift)) % 4)}
+			}
+
+			return evaluateCards(t, cards) == evaluateCards(t, renamed)
+		}
+
+		if err := quick.Check(assertion, nil); err != nil {
+			t.Error("failed checks", err)
+		}
+	})
+}
+
+func bestOfFive(t testing.TB, cards []Card) HandRank {
+	t.Helper()
+
+	var best HandRank
+
+	for skipA := 0; skipA < len(cards); skipA++ {
+		for skipB := skipA + 1; skipB < len(cards); skipB++ {
+			var hand []Card
+
+			for i, c := range cards {
+				if i != skipA && i != skipB {
+					hand = append(hand, c)
+				}
+			}
+
+			if rank := evaluateCards(t, hand); rank > best {
+				best = rank
+			}
+		}
+	}
+
+	return best
+}
+
+func evaluate(t testing.TB, cards string) HandRank {
+	t.Helper()
+	return evaluateCards(t, mustParseCards(t, cards))
+}
+
+func evaluateCards(t testing.TB, cards []Card) HandRank {
+	t.Helper()
+
+	rank, err := Evaluate(cards)
+	assertNoError(t, err)
+
+	return rank
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	cards, _ := NewShuffledDeck(1).Deal(7)
+
+	for i := 0; i < b.N; i++ {
+		Evaluate(cards)
+	}
+}
@@ -0,0 +1,280 @@
+// Package client is a Go client for the poker HTTP API served by poker.PlayerServer.
+package client
+
+import (
+	"bytes"
+	poker "command-line-and-project-structure"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrPlayerNotFound is returned by Score for players who have never won.
+var ErrPlayerNotFound = errors.New("player not found")
+
+// APIError is returned when the server answers with an unexpected status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("poker API returned %d %s, %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Client talks to a poker server.
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	timeout *time.Duration
+	retries int
+	backoff time.Duration
+	actor   string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests with httpClient instead of a default client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.http = httpClient
+	}
+}
+
+// WithTimeout limits how long each attempt at a request may take. It applies to a client
+// given with WithHTTPClient too, in either order, without changing that client.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = &timeout
+	}
+}
+
+// WithRetries retries failed requests up to retries times, waiting backoff
+// before the first retry and doubling the wait before each one after.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// WithActor names who is making requests. The server cannot check the name, so its audit
+// log keeps it as a claim alongside the client's address or token.
+func WithActor(actor string) Option {
+	return func(c *Client) {
+		c.actor = actor
+	}
+}
+
+// New creates a Client for the server at baseURL, e.g. "http://localhost:4000".
+func New(baseURL string, options ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing server url %q, %v", baseURL, err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("server url %q must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL: u,
+		http:    &http.Client{Timeout: 10 * time.Second},
+		retries: 3,
+		backoff: 100 * time.Millisecond,
+	}
+
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.timeout != nil {
+		httpClient := *c.http
+		httpClient.Timeout = *c.timeout
+		c.http = &httpClient
+	}
+
+	return c, nil
+}
+
+// RecordWin records a win for player. Retries carry the same Idempotency-Key,
+// so a win is only counted once even if a response is lost.
+func (c *Client) RecordWin(ctx context.Context, player string) error {
+	header := http.Header{"Idempotency-Key": {newIdempotencyKey()}}
+
+	response, err := c.do(ctx, http.MethodPost, playerPath(player), header)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusAccepted {
+		return decodeError(response)
+	}
+
+	return nil
+}
+
+// Score returns how many games player has won, or ErrPlayerNotFound if they never have.
+func (c *Client) Score(ctx context.Context, player string) (int, error) {
+	response, err := c.do(ctx, http.MethodGet, playerPath(player), nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return 0, ErrPlayerNotFound
+	default:
+		return 0, decodeError(response)
+	}
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return 0, fmt.Errorf("problem reading score for %s, %v", player, err)
+	}
+
+	score, err := strconv.Atoi(strings.TrimSpace(string(body)))
+
+	if err != nil {
+		return 0, fmt.Errorf("problem parsing score for %s, %v", player, err)
+	}
+
+	return score, nil
+}
+
+// Account returns player's chip balance and the transactions that make it up.
+func (c *Client) Account(ctx context.Context, player string) (poker.Account, error) {
+	response, err := c.do(ctx, http.MethodGet, playerPath(player)+"/balance", nil)
+
+	if err != nil {
+		return poker.Account{}, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return poker.Account{}, decodeError(response)
+	}
+
+	var account poker.Account
+
+	if err := json.NewDecoder(response.Body).Decode(&account); err != nil {
+		return poker.Account{}, fmt.Errorf("problem parsing account for %s, %v", player, err)
+	}
+
+	return account, nil
+}
+
+// League returns every player, most wins first.
+func (c *Client) League(ctx context.Context) (poker.League, error) {
+	response, err := c.do(ctx, http.MethodGet, "/league", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, decodeError(response)
+	}
+
+	return poker.NewLeague(response.Body)
+}
+
+// do sends a request, retrying network errors and responses that suggest the
+// server may succeed if asked again.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header) (*http.Response, error) {
+	wait := c.backoff
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.send(ctx, method, path, header)
+
+		if (err == nil && !retryable(response.StatusCode)) || attempt >= c.retries || ctx.Err() != nil {
+			return response, err
+		}
+
+		if err == nil {
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+
+		select {
+		case <-time.After(wait):
+			wait *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, method, path string, header http.Header) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL.String()+path, nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem creating request, %v", err)
+	}
+
+	for key, values := range header {
+		request.Header[key] = values
+	}
+
+	if c.actor != "" {
+		request.Header.Set("X-Actor", c.actor)
+	}
+
+	response, err := c.http.Do(request)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem calling poker API, %w", err)
+	}
+
+	return response, nil
+}
+
+func retryable(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusTooManyRequests:
+		return true
+	default:
+		return false
+	}
+}
+
+func decodeError(response *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+
+	return &APIError{
+		StatusCode: response.StatusCode,
+		Message:    string(bytes.TrimSpace(body)),
+	}
+}
+
+func playerPath(player string) string {
+	return "/players/" + url.PathEscape(player)
+}
+
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	rand.Read(key)
+	return hex.EncodeToString(key)
+}
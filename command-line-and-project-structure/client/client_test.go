@@ -0,0 +1,243 @@
+package client
+
+import (
+	poker "command-line-and-project-structure"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(poker.NewPlayerServer(&poker.StubPlayerStore{}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	ctx := context.Background()
+
+	t.Run("records wins and reports scores", func(t *testing.T) {
+		assertNoError(t, client.RecordWin(ctx, "Pepper"))
+		assertNoError(t, client.RecordWin(ctx, "Pepper"))
+
+		score, err := client.Score(ctx, "Pepper")
+		assertNoError(t, err)
+
+		if score != 2 {
+			t.Errorf("got score %d want %d", score, 2)
+		}
+	})
+
+	t.Run("escapes player names", func(t *testing.T) {
+		assertNoError(t, client.RecordWin(ctx, "Chris Jones/Jr?"))
+
+		score, err := client.Score(ctx, "Chris Jones/Jr?")
+		assertNoError(t, err)
+
+		if score != 1 {
+			t.Errorf("got score %d want %d", score, 1)
+		}
+	})
+
+	t.Run("reports unknown players", func(t *testing.T) {
+		_, err := client.Score(ctx, "Apollo")
+
+		if !errors.Is(err, ErrPlayerNotFound) {
+			t.Errorf("got %v want %v", err, ErrPlayerNotFound)
+		}
+	})
+
+	t.Run("returns a player's account", func(t *testing.T) {
+		account, err := client.Account(ctx, "Pepper")
+		assertNoError(t, err)
+
+		if account.Player != "Pepper" || account.Balance != 0 {
+			t.Errorf("unexpected account %+v", account)
+		}
+	})
+
+	t.Run("returns the league", func(t *testing.T) {
+		league, err := client.League(ctx)
+		assertNoError(t, err)
+
+		if len(league) != 2 || league[0].Name != "Pepper" {
+			t.Errorf("unexpected league %v", league)
+		}
+	})
+}
+
+func TestClientRetries(t *testing.T) {
+	t.Run("retries unavailable servers with the same idempotency key", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		players := poker.NewPlayerServer(store)
+		failures := 2
+		var keys []string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			players.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		client := newTestClient(t, server.URL)
+
+		assertNoError(t, client.RecordWin(context.Background(), "Pepper"))
+
+		if len(keys) != 3 || keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+			t.Errorf("expected 3 attempts with the same key, got %q", keys)
+		}
+
+		poker.AssertPlayerWin(t, store, "Pepper")
+	})
+
+	t.Run("gives up after the last retry", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := newTestClient(t, server.URL)
+
+		_, err := client.League(context.Background())
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("expected an APIError but got %v", err)
+		}
+
+		if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Message != "down for maintenance" {
+			t.Errorf("unexpected error %+v", apiErr)
+		}
+
+		if attempts != 3 {
+			t.Errorf("got %d attempts want %d", attempts, 3)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			http.Error(w, "no", http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		client := newTestClient(t, server.URL)
+		client.League(context.Background())
+
+		if attempts != 1 {
+			t.Errorf("got %d attempts want %d", attempts, 1)
+		}
+	})
+
+	t.Run("times out slow servers", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		client := newTestClient(t, server.URL, WithTimeout(10*time.Millisecond), WithRetries(0, 0))
+
+		_, err := client.League(context.Background())
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("times out with a given http client without changing it", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer server.Close()
+		defer close(release)
+
+		timeout := WithTimeout(10 * time.Millisecond)
+
+		for _, timeoutFirst := range []bool{true, false} {
+			httpClient := &http.Client{}
+			options := []Option{WithRetries(0, 0), WithHTTPClient(httpClient)}
+
+			if timeoutFirst {
+				options = append([]Option{timeout}, options...)
+			} else {
+				options = append(options, timeout)
+			}
+
+			client := newTestClient(t, server.URL, options...)
+
+			if _, err := client.League(context.Background()); err == nil {
+				t.Error("expected an error but didn't get one")
+			}
+
+			if httpClient.Timeout != 0 {
+				t.Errorf("given http client's timeout was changed to %v", httpClient.Timeout)
+			}
+		}
+	})
+}
+
+func TestSubscribeLeague(t *testing.T) {
+	server := httptest.NewServer(poker.NewPlayerServer(&poker.StubPlayerStore{}))
+	defer server.Close()
+
+	client := newTestClient(t, server.URL)
+	ctx := context.Background()
+
+	subscription, err := client.SubscribeLeague(ctx)
+	assertNoError(t, err)
+
+	league, err := subscription.Next()
+	assertNoError(t, err)
+	assertLeague(t, league, poker.League{})
+
+	assertNoError(t, client.RecordWin(ctx, "Cleo"))
+
+	league, err = subscription.Next()
+	assertNoError(t, err)
+	assertLeague(t, league, poker.League{{Name: "Cleo", Wins: 1}})
+
+	assertNoError(t, subscription.Close())
+
+	if _, err := subscription.Next(); err == nil {
+		t.Error("expected an error reading a closed subscription but didn't get one")
+	}
+}
+
+func newTestClient(t testing.TB, url string, options ...Option) *Client {
+	t.Helper()
+
+	options = append([]Option{WithRetries(2, time.Millisecond)}, options...)
+	client, err := New(url, options...)
+	assertNoError(t, err)
+
+	return client
+}
+
+func assertLeague(t testing.TB, got, want poker.League) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func assertNoError(t testing.TB, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("didn't expect an error but got one, %v", err)
+	}
+}
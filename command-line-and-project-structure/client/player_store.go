@@ -0,0 +1,57 @@
+package client
+
+import (
+	poker "command-line-and-project-structure"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// PlayerStore is a poker.PlayerStore kept by a remote poker server, so the CLI
+// can record wins into a central league without access to its database.
+type PlayerStore struct {
+	client *Client
+}
+
+// NewPlayerStore creates a PlayerStore that reads and records wins through client.
+func NewPlayerStore(client *Client) *PlayerStore {
+	return &PlayerStore{client: client}
+}
+
+// GetPlayerScore retrieves a player's score, which is 0 for players who have never won.
+func (p *PlayerStore) GetPlayerScore(ctx context.Context, name string) (int, error) {
+	score, err := p.client.Score(ctx, name)
+
+	if errors.Is(err, ErrPlayerNotFound) {
+		return 0, nil
+	}
+
+	return score, storeError(err)
+}
+
+// RecordWin records a win for a player on the server.
+func (p *PlayerStore) RecordWin(ctx context.Context, name string) error {
+	return storeError(p.client.RecordWin(ctx, name))
+}
+
+// GetLeague returns the scores of all the players.
+func (p *PlayerStore) GetLeague(ctx context.Context) (poker.League, error) {
+	league, err := p.client.League(ctx)
+	return league, storeError(err)
+}
+
+// storeError reports a server that couldn't be reached or had no store available
+// as poker.ErrStoreUnavailable, like the stores the server itself uses.
+func storeError(err error) error {
+	var apiErr *APIError
+
+	switch {
+	case err == nil, errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	case errors.As(err, &apiErr) && apiErr.StatusCode != http.StatusServiceUnavailable:
+		return err
+	default:
+		return fmt.Errorf("%w: %v", poker.ErrStoreUnavailable, err)
+	}
+}
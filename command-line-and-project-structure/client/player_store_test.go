@@ -0,0 +1,92 @@
+package client
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/storetest"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlayerStoreContract(t *testing.T) {
+	storetest.RunPlayerStoreContract(t, func(t *testing.T) storetest.Subject {
+		backing, close, err := poker.FileSystemFileStoreFromFile(filepath.Join(t.TempDir(), "game.db.json"))
+		assertNoError(t, err)
+		t.Cleanup(close)
+
+		server := httptest.NewServer(poker.NewPlayerServer(backing))
+		t.Cleanup(server.Close)
+
+		open := func() poker.PlayerStore {
+			return NewPlayerStore(newTestClient(t, server.URL))
+		}
+
+		return storetest.Subject{Store: open(), Reopen: open}
+	})
+}
+
+func TestPlayerStore(t *testing.T) {
+	t.Run("reports unreachable servers as unavailable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		store := NewPlayerStore(newTestClient(t, server.URL))
+
+		err := store.RecordWin(context.Background(), "Pepper")
+
+		if !errors.Is(err, poker.ErrStoreUnavailable) {
+			t.Errorf("got %v want %v", err, poker.ErrStoreUnavailable)
+		}
+	})
+
+	t.Run("passes on other server errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "disk full", http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := NewPlayerStore(newTestClient(t, server.URL))
+
+		_, err := store.GetLeague(context.Background())
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || errors.Is(err, poker.ErrStoreUnavailable) {
+			t.Errorf("expected an APIError but got %v", err)
+		}
+	})
+
+	t.Run("the CLI records wins on the server as a claimed actor", func(t *testing.T) {
+		dir := t.TempDir()
+
+		auditLog, closeAudit, err := poker.AuditLogFromFile(filepath.Join(dir, "game.audit.jsonl"))
+		assertNoError(t, err)
+		defer closeAudit()
+
+		backing, closeBacking, err := poker.FileSystemFileStoreFromFile(filepath.Join(dir, "game.db.json"), poker.WithStoreAuditLog(auditLog, poker.Origin{}))
+		assertNoError(t, err)
+		defer closeBacking()
+
+		server := httptest.NewServer(poker.NewPlayerServer(backing, poker.WithAuditLog(auditLog)))
+		defer server.Close()
+
+		store := NewPlayerStore(newTestClient(t, server.URL, WithActor("chris")))
+		cli := poker.NewCLI(store, strings.NewReader("Cleo wins\n"))
+
+		assertNoError(t, cli.PlayPoker())
+
+		if score, _ := backing.GetPlayerScore(context.Background(), "Cleo"); score != 1 {
+			t.Errorf("got score %d want 1", score)
+		}
+
+		entries, err := auditLog.Entries("Cleo")
+		assertNoError(t, err)
+
+		if len(entries) != 1 || entries[0].ClaimedActor != "chris" || entries[0].Source != poker.AuditSourceHTTP {
+			t.Errorf("expected one win audited as claimed by chris but got %+v", entries)
+		}
+	})
+}
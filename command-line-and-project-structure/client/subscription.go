@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bufio"
+	poker "command-line-and-project-structure"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LeagueSubscription receives the league every time the server reports it changed.
+type LeagueSubscription struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// SubscribeLeague streams the league, starting with its current state.
+// Cancel ctx or call Close to stop the subscription.
+func (c *Client) SubscribeLeague(ctx context.Context) (*LeagueSubscription, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL.String()+"/league/stream", nil)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem creating request, %v", err)
+	}
+
+	request.Header.Set("Accept", "text/event-stream")
+
+	// the stream stays open for as long as the subscription, so it must not be
+	// cut short by the timeout on ordinary requests.
+	streaming := *c.http
+	streaming.Timeout = 0
+
+	response, err := streaming.Do(request)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem calling poker API, %w", err)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		defer response.Body.Close()
+		return nil, decodeError(response)
+	}
+
+	return &LeagueSubscription{
+		body:    response.Body,
+		scanner: bufio.NewScanner(response.Body),
+	}, nil
+}
+
+// Next waits for the next league, returning io.EOF once the stream has ended.
+func (s *LeagueSubscription) Next() (poker.League, error) {
+	event, data := "", ""
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		case line == "" && event == "error":
+			return nil, fmt.Errorf("league stream failed, %s", data)
+		case line == "" && data != "":
+			var league poker.League
+
+			if err := json.Unmarshal([]byte(data), &league); err != nil {
+				return nil, fmt.Errorf("problem parsing league event, %v", err)
+			}
+
+			return league, nil
+		}
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+// Close ends the subscription.
+func (s *LeagueSubscription) Close() error {
+	return s.body.Close()
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func bankCommand() *command {
+	return &command{
+		name:    "bank",
+		summary: "Record chip transactions in the ledger or print a player's account.",
+		subcommands: []*command{
+			{
+				name:    "balance",
+				args:    "PLAYER",
+				summary: "Print a player's transactions and balance.",
+				minArgs: 1,
+				maxArgs: 1,
+				players: true,
+				setup:   bankRunner(nil),
+			},
+			{
+				name:    "deposit",
+				args:    "PLAYER CHIPS",
+				summary: "Add chips to a player's balance.",
+				minArgs: 2,
+				maxArgs: 2,
+				players: true,
+				setup: bankRunner(func(args []string, amount poker.Chips) poker.Transaction {
+					return poker.Deposit(args[0], amount)
+				}),
+			},
+			{
+				name:    "withdraw",
+				args:    "PLAYER CHIPS",
+				summary: "Take chips out of a player's balance.",
+				minArgs: 2,
+				maxArgs: 2,
+				players: true,
+				setup: bankRunner(func(args []string, amount poker.Chips) poker.Transaction {
+					return poker.Withdrawal(args[0], amount)
+				}),
+			},
+			{
+				name:    "buy-in",
+				args:    "PLAYER GAME CHIPS",
+				summary: "Pay a player's buy-in to a game from their balance.",
+				minArgs: 3,
+				maxArgs: 3,
+				players: true,
+				setup: bankRunner(func(args []string, amount poker.Chips) poker.Transaction {
+					return poker.BuyIn(args[0], args[1], amount)
+				}),
+			},
+			{
+				name:    "payout",
+				args:    "PLAYER GAME CHIPS",
+				summary: "Pay a player's winnings from a game into their balance.",
+				minArgs: 3,
+				maxArgs: 3,
+				players: true,
+				setup: bankRunner(func(args []string, amount poker.Chips) poker.Transaction {
+					return poker.Payout(args[0], args[1], amount)
+				}),
+			},
+		},
+	}
+}
+
+// bankRunner records the transaction made from the arguments, whose last is the number of
+// chips, then prints the player's account. With no transaction it only prints the account.
+func bankRunner(transaction func(args []string, amount poker.Chips) poker.Transaction) func(*flag.FlagSet) func([]string) error {
+	return func(*flag.FlagSet) func([]string) error {
+		return func(args []string) error {
+			var amount int
+
+			if transaction != nil {
+				var err error
+
+				if amount, err = strconv.Atoi(args[len(args)-1]); err != nil {
+					return usagef("problem parsing chips %q, %v", args[len(args)-1], err)
+				}
+			}
+
+			store, close, err := bootstrap.OpenFileStore()
+
+			if err != nil {
+				return err
+			}
+
+			defer close()
+
+			ctx := context.Background()
+
+			if transaction != nil {
+				if err := store.RecordTransaction(ctx, transaction(args, poker.Chips(amount))); err != nil {
+					return err
+				}
+			}
+
+			return printAccount(ctx, store, args[0])
+		}
+	}
+}
+
+func printAccount(ctx context.Context, store poker.BankPlayerStore, player string) error {
+	account, err := store.GetAccount(ctx, player)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tKIND\tGAME\tCHIPS")
+
+	for _, t := range account.Transactions {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", t.Time.Format(time.RFC3339), t.Kind, t.Game, t.Amount)
+	}
+
+	fmt.Fprintf(w, "\t\tBALANCE\t%d\n", account.Balance)
+
+	return w.Flush()
+}
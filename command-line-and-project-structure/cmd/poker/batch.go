@@ -0,0 +1,61 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"flag"
+	"io"
+	"os"
+)
+
+func batchCommand() *command {
+	return &command{
+		name:    "batch",
+		args:    "[FILE]",
+		summary: "Record every {name} wins line in FILE, or stdin if there is no FILE or it is -, in one transaction.",
+		maxArgs: 1,
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			var config bootstrap.Config
+			config.RegisterStoreFlags(flags)
+			dryRun := flags.Bool("dry-run", false, "show the league the batch would make without recording it")
+			return func(args []string) error {
+				name := ""
+
+				if len(args) > 0 {
+					name = args[0]
+				}
+
+				return batch(config, name, *dryRun)
+			}
+		},
+	}
+}
+
+// batch records every win in the file name, or stdin if name is empty or -, in one transaction,
+// in the store config says.
+func batch(config bootstrap.Config, name string, dryRun bool) error {
+	var in io.Reader = os.Stdin
+
+	if name != "" && name != "-" {
+		file, err := os.Open(name)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+		in = file
+	}
+
+	store, close, err := config.OpenStore()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	cli := poker.NewCLI(store, in, poker.WithCLIOutput(os.Stdout))
+
+	return cli.PlayBatch(dryRun)
+}
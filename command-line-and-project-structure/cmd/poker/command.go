@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Exit codes shared by every command.
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command is a node in the command tree. It runs, groups subcommands, or both, in
+// which case it runs when no subcommand is given.
+type command struct {
+	name    string
+	args    string
+	summary string
+	// minArgs and maxArgs bound how many positional arguments it takes; a negative maxArgs is no limit.
+	minArgs, maxArgs int
+	// players completes the first positional argument with player names from the store.
+	players bool
+	hidden  bool
+	// setup defines the command's flags and returns what to run once they are parsed.
+	setup       func(flags *flag.FlagSet) func(args []string) error
+	subcommands []*command
+}
+
+// usageError is returned by a command given arguments it can't use.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, a ...interface{}) error {
+	return usageError{fmt.Sprintf(format, a...)}
+}
+
+func (c *command) find(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+	}
+
+	return nil
+}
+
+// flagSet returns the command's flags along with what to run, which is nil for a group.
+func (c *command) flagSet(path string) (*flag.FlagSet, func(args []string) error) {
+	flags := flag.NewFlagSet(path, flag.ContinueOnError)
+	flags.Usage = func() {}
+
+	if c.setup == nil {
+		return flags, nil
+	}
+
+	return flags, c.setup(flags)
+}
+
+// execute runs the command named by args under c, returning the exit code.
+func (c *command) execute(path string, args []string, stdout, stderr io.Writer) int {
+	if len(c.subcommands) > 0 && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if sub := c.find(args[0]); sub != nil {
+			return sub.execute(path+" "+sub.name, args[1:], stdout, stderr)
+		}
+
+		if c.setup == nil || c.maxArgs == 0 {
+			fmt.Fprintf(stderr, "%s: unknown command %q\n\n", path, args[0])
+			c.printHelp(stderr, path)
+			return exitUsage
+		}
+	}
+
+	flags, run := c.flagSet(path)
+	flags.SetOutput(stderr)
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			c.printHelp(stdout, path)
+			return exitOK
+		}
+
+		fmt.Fprintln(stderr)
+		c.printHelp(stderr, path)
+		return exitUsage
+	}
+
+	if run == nil {
+		c.printHelp(stderr, path)
+		return exitUsage
+	}
+
+	if n := flags.NArg(); n < c.minArgs || (c.maxArgs >= 0 && n > c.maxArgs) {
+		fmt.Fprintf(stderr, "%s: wrong number of arguments\n\n", path)
+		c.printHelp(stderr, path)
+		return exitUsage
+	}
+
+	if err := run(flags.Args()); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+
+		var usage usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintln(stderr)
+			c.printHelp(stderr, path)
+			return exitUsage
+		}
+
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func (c *command) printHelp(w io.Writer, path string) {
+	synopsis := path
+
+	switch {
+	case len(c.subcommands) > 0 && c.setup != nil:
+		synopsis += " [COMMAND]"
+	case len(c.subcommands) > 0:
+		synopsis += " COMMAND"
+	}
+
+	flags, _ := c.flagSet(path)
+	hasFlags := false
+	flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+
+	if hasFlags {
+		synopsis += " [flags]"
+	}
+
+	if c.args != "" {
+		synopsis += " " + c.args
+	}
+
+	fmt.Fprintf(w, "usage: %s\n\n%s\n", synopsis, c.summary)
+
+	if len(c.subcommands) > 0 {
+		fmt.Fprintf(w, "\ncommands:\n")
+
+		for _, sub := range c.subcommands {
+			if !sub.hidden {
+				fmt.Fprintf(w, "  %-12s %s\n", sub.name, sub.summary)
+			}
+		}
+	}
+
+	if hasFlags {
+		fmt.Fprintf(w, "\nflags:\n")
+		flags.SetOutput(w)
+		flags.PrintDefaults()
+	}
+
+	if len(c.subcommands) > 0 {
+		fmt.Fprintf(w, "\nRun \"%s COMMAND --help\" for more about a command.\n", path)
+	}
+}
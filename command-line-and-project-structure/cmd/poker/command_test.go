@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	var greeted string
+
+	tree := &command{
+		name:    "test",
+		summary: "Test the command tree.",
+		subcommands: []*command{
+			{
+				name:    "greet",
+				args:    "NAME",
+				summary: "Greet someone.",
+				minArgs: 1,
+				maxArgs: 1,
+				setup: func(flags *flag.FlagSet) func([]string) error {
+					loud := flags.Bool("loud", false, "greet loudly")
+					return func(args []string) error {
+						greeted = args[0]
+
+						if *loud {
+							greeted = strings.ToUpper(greeted)
+						}
+
+						return nil
+					}
+				},
+			},
+			{
+				name:    "fail",
+				summary: "Always fail.",
+				setup: func(*flag.FlagSet) func([]string) error {
+					return func([]string) error { return errors.New("it broke") }
+				},
+			},
+			{
+				name:    "misuse",
+				summary: "Always complain about how it was used.",
+				setup: func(*flag.FlagSet) func([]string) error {
+					return func([]string) error { return usagef("that's not how to use it") }
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{"runs a subcommand", []string{"greet", "Cleo"}, exitOK, "", ""},
+		{"prints help when asked", []string{"greet", "--help"}, exitOK, "usage: test greet [flags] NAME", ""},
+		{"lists subcommands in a group's help", []string{"-help"}, exitOK, "  greet        Greet someone.", ""},
+		{"reports a failed command", []string{"fail"}, exitFailure, "", "test fail: it broke"},
+		{"rejects unknown commands", []string{"fold"}, exitUsage, "", `test: unknown command "fold"`},
+		{"rejects a group without a command", nil, exitUsage, "", "usage: test COMMAND"},
+		{"rejects too few arguments", []string{"greet"}, exitUsage, "", "test greet: wrong number of arguments"},
+		{"rejects too many arguments", []string{"greet", "Cleo", "Chris"}, exitUsage, "", "test greet: wrong number of arguments"},
+		{"rejects unknown flags", []string{"greet", "-quiet", "Cleo"}, exitUsage, "", "flag provided but not defined: -quiet"},
+		{"rejects misuse reported by the command", []string{"misuse"}, exitUsage, "", "test misuse: that's not how to use it"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := tree.execute(tree.name, c.args, &stdout, &stderr)
+
+			if code != c.wantCode {
+				t.Errorf("got exit code %d want %d, stderr %q", code, c.wantCode, stderr.String())
+			}
+
+			assertOutput(t, "stdout", stdout.String(), c.wantStdout)
+			assertOutput(t, "stderr", stderr.String(), c.wantStderr)
+		})
+	}
+
+	t.Run("passes flags to the command", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+
+		if code := tree.execute(tree.name, []string{"greet", "-loud", "Cleo"}, &stdout, &stderr); code != exitOK {
+			t.Fatalf("got exit code %d want %d, stderr %q", code, exitOK, stderr.String())
+		}
+
+		if greeted != "CLEO" {
+			t.Errorf("got greeted %q want %q", greeted, "CLEO")
+		}
+	})
+}
+
+// assertOutput checks the output named name contains want, or is empty if want is.
+func assertOutput(t testing.TB, name, got, want string) {
+	t.Helper()
+
+	if want == "" && got != "" {
+		t.Errorf("expected no %s but got %q", name, got)
+	}
+
+	if !strings.Contains(got, want) {
+		t.Errorf("got %s %q want it to contain %q", name, got, want)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// completeCommandName is the hidden command the completion scripts ask for suggestions.
+const completeCommandName = "__complete"
+
+// playersTimeout is how long completion waits for the store before suggesting no players.
+const playersTimeout = 2 * time.Second
+
+var completionScripts = map[string]string{
+	"bash": `# bash completion for {{.}}, load with: source <({{.}} completion bash)
+_{{.}}() {
+	local IFS=$'\n'
+	COMPREPLY=($({{.}} {{complete}} -- "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _{{.}} {{.}}
+`,
+	"zsh": `#compdef {{.}}
+# zsh completion for {{.}}, load with: source <({{.}} completion zsh)
+_{{.}}() {
+	local output
+	output="$({{.}} {{complete}} -- "${(@)words[2,CURRENT]}" 2>/dev/null)"
+	[[ -n $output ]] || return 1
+	local -a completions
+	completions=("${(@f)output}")
+	compadd -a completions
+}
+
+if [[ "$funcstack[1]" == "_{{.}}" ]]; then
+	_{{.}} "$@"
+else
+	compdef _{{.}} {{.}}
+fi
+`,
+	"fish": `# fish completion for {{.}}, load with: {{.}} completion fish | source
+function __{{.}}_complete
+	set -l words (commandline -opc)
+	set -e words[1]
+	{{.}} {{complete}} -- $words (commandline -ct) 2>/dev/null
+end
+complete -c {{.}} -f -a '(__{{.}}_complete)'
+`,
+}
+
+func completionCommand() *command {
+	c := &command{
+		name:    "completion",
+		summary: "Print a script that completes commands, flags and player names in your shell.",
+	}
+
+	for _, shell := range []string{"bash", "fish", "zsh"} {
+		shell := shell
+		c.subcommands = append(c.subcommands, &command{
+			name:    shell,
+			summary: fmt.Sprintf("Print the %s completion script.", shell),
+			setup: func(*flag.FlagSet) func([]string) error {
+				return func([]string) error { return writeCompletionScript(os.Stdout, shell) }
+			},
+		})
+	}
+
+	return c
+}
+
+func writeCompletionScript(w io.Writer, shell string) error {
+	script := template.Must(template.New(shell).
+		Funcs(template.FuncMap{"complete": func() string { return completeCommandName }}).
+		Parse(completionScripts[shell]))
+
+	return script.Execute(w, programName)
+}
+
+func completeCommand() *command {
+	return &command{
+		name:    completeCommandName,
+		args:    "-- WORD...",
+		summary: "Print the words that could complete the last WORD, one to a line.",
+		maxArgs: -1,
+		hidden:  true,
+		setup: func(*flag.FlagSet) func([]string) error {
+			return func(words []string) error {
+				for _, suggestion := range complete(root(), words) {
+					fmt.Println(suggestion)
+				}
+				return nil
+			}
+		},
+	}
+}
+
+// complete suggests what could be typed for the last of words, the command line after the program name.
+func complete(root *command, words []string) []string {
+	current := ""
+
+	if len(words) > 0 {
+		current, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	c := root
+
+	for len(words) > 0 {
+		sub := c.find(words[0])
+
+		if sub == nil || sub.hidden {
+			break
+		}
+
+		c, words = sub, words[1:]
+	}
+
+	flags, _ := c.flagSet(c.name)
+	flags.SetOutput(io.Discard)
+
+	if len(words) > 0 && takesValue(flags, words[len(words)-1]) {
+		return nil
+	}
+
+	var candidates []string
+
+	if strings.HasPrefix(current, "-") {
+		flags.VisitAll(func(f *flag.Flag) { candidates = append(candidates, "-"+f.Name) })
+		candidates = append(candidates, "-help")
+		return withPrefix(candidates, current)
+	}
+
+	flags.Parse(words)
+
+	if len(words) == 0 {
+		for _, sub := range c.subcommands {
+			if !sub.hidden {
+				candidates = append(candidates, sub.name)
+			}
+		}
+	}
+
+	if c.players && flags.NArg() == 0 {
+		var store storeFlags
+
+		if f := flags.Lookup("server"); f != nil {
+			store.server = f.Value.String()
+		}
+
+		if f := flags.Lookup("redis"); f != nil {
+			store.config.RedisAddr = f.Value.String()
+		}
+
+		if f := flags.Lookup("redis-key"); f != nil {
+			store.config.RedisKey = f.Value.String()
+		}
+
+		candidates = append(candidates, players(store)...)
+	}
+
+	return withPrefix(candidates, current)
+}
+
+// takesValue reports whether word is a flag that takes the next word as its value.
+func takesValue(flags *flag.FlagSet, word string) bool {
+	if !strings.HasPrefix(word, "-") || strings.Contains(word, "=") {
+		return false
+	}
+
+	f := flags.Lookup(strings.TrimLeft(word, "-"))
+
+	if f == nil {
+		return false
+	}
+
+	boolean, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return !ok || !boolean.IsBoolFlag()
+}
+
+// players names everyone in the league store says. Completion is best effort, so any problem
+// suggests nobody.
+func players(store storeFlags) []string {
+	league, err := completionLeague(store)
+
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, len(league))
+
+	for i, p := range league {
+		names[i] = p.Name
+	}
+
+	return names
+}
+
+// completionLeague reads the league to complete player names from. A local league is only
+// read, as completion runs on every tab press and must never migrate, lock or write it.
+func completionLeague(flags storeFlags) (poker.League, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), playersTimeout)
+	defer cancel()
+
+	if flags.server == "" {
+		return flags.config.ReadLeague(ctx)
+	}
+
+	store, close, err := openServer(flags.server)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer close()
+
+	return store.GetLeague(ctx)
+}
+
+func withPrefix(words []string, prefix string) []string {
+	var matching []string
+
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			matching = append(matching, word)
+		}
+	}
+
+	return matching
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"command-line-and-project-structure/redistest"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestComplete(t *testing.T) {
+	database := `[{"Name": "Cleo", "Wins": 1}, {"Name": "Chris", "Wins": 3}]`
+	inTempDir(t)
+
+	if err := os.WriteFile(bootstrap.DBFileName, []byte(database), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		words []string
+		want  []string
+	}{
+		{[]string{""}, []string{"play", "league", "score", "leaderboard", "batch", "audit", "export", "import", "backup", "restore", "rekey", "season", "bank", "serve", "completion"}},
+		{[]string{"ba"}, []string{"batch", "backup", "bank"}},
+		{[]string{"bank", "d"}, []string{"deposit"}},
+		{[]string{"completion", ""}, []string{"bash", "fish", "zsh"}},
+		{[]string{"score", "-s"}, []string{"-server"}},
+		{[]string{"score", "-server", ""}, nil},
+		{[]string{"score", ""}, []string{"Chris", "Cleo"}},
+		{[]string{"bank", "balance", "Cl"}, []string{"Cleo"}},
+		{[]string{"score", "Cleo", ""}, nil},
+		{[]string{"__complete", ""}, nil},
+	}
+
+	for _, c := range cases {
+		got := complete(root(), c.words)
+
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("completing %q got %q want %q", c.words, got, c.want)
+		}
+	}
+
+	t.Run("reads the database without migrating or writing it", func(t *testing.T) {
+		onDisk, err := os.ReadFile(bootstrap.DBFileName)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(onDisk) != database {
+			t.Errorf("database was rewritten to %q", onDisk)
+		}
+
+		entries, _ := os.ReadDir(".")
+
+		if len(entries) != 1 {
+			t.Errorf("expected only %s in the directory but found %d files", bootstrap.DBFileName, len(entries))
+		}
+	})
+
+	t.Run("suggests players from the Redis store the flags name", func(t *testing.T) {
+		server, err := redistest.NewServer()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer server.Close()
+
+		store := poker.NewRedisPlayerStore(server.Addr(), "league")
+		defer store.Close()
+
+		if err := store.RecordWin(context.Background(), "Pepper"); err != nil {
+			t.Fatal(err)
+		}
+
+		got := complete(root(), []string{"score", "-redis", server.Addr(), "-redis-key", "league", ""})
+
+		if !reflect.DeepEqual(got, []string{"Pepper"}) {
+			t.Errorf("got %q want %q", got, []string{"Pepper"})
+		}
+	})
+
+	t.Run("suggests no players without a database", func(t *testing.T) {
+		inTempDir(t)
+
+		if got := complete(root(), []string{"score", ""}); got != nil {
+			t.Errorf("got %q want no suggestions", got)
+		}
+
+		if _, err := os.Stat(bootstrap.DBFileName); !os.IsNotExist(err) {
+			t.Errorf("expected no database to be created, got %v", err)
+		}
+	})
+}
+
+// inTempDir runs the rest of the test in a new temporary directory.
+func inTempDir(t testing.TB) {
+	t.Helper()
+
+	wd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.Chdir(wd) })
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"command-line-and-project-structure/leaderboard"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+)
+
+func leaderboardCommand() *command {
+	return &command{
+		name:    "leaderboard",
+		summary: "Draw the league in the terminal, refreshing it until interrupted.",
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			store := addStoreFlags(flags)
+			every := flags.Duration("every", 5*time.Second, "how often to refresh the league")
+			once := flags.Bool("once", false, "draw the league once and exit")
+			width := flags.Int("width", terminalWidth(), "how many columns to fit the table in")
+			return func([]string) error {
+				if *every <= 0 {
+					return usagef("-every must be positive")
+				}
+
+				return showLeaderboard(store, *every, *once, *width)
+			}
+		},
+	}
+}
+
+// showLeaderboard draws the league in the terminal, refreshing it every so often until interrupted.
+// It reads the league from the store flags says.
+func showLeaderboard(flags *storeFlags, every time.Duration, once bool, width int) error {
+	store, close, err := flags.open()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	terminal := isTerminal(os.Stdout)
+	board := leaderboard.New(
+		leaderboard.WithWidth(width),
+		leaderboard.WithColour(terminal && os.Getenv("NO_COLOR") == ""),
+		leaderboard.WithRedraw(terminal && !once),
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		league, err := store.GetLeague(ctx)
+
+		if err != nil && once {
+			return err
+		}
+
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not refresh the league, %v\n", err)
+		} else if err := board.Render(os.Stdout, league, time.Now()); err != nil {
+			return err
+		}
+
+		if once {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// terminalWidth is the width of the terminal stdout is attached to, else as exported in
+// $COLUMNS, or the leaderboard's default.
+func terminalWidth() int {
+	if columns, ok := ttyWidth(os.Stdout); ok {
+		return columns
+	}
+
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+
+	return leaderboard.DefaultWidth
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
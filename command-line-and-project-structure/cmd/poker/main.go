@@ -0,0 +1,230 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"command-line-and-project-structure/client"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const programName = "poker"
+
+func main() {
+	os.Exit(root().execute(programName, os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func root() *command {
+	return &command{
+		name: programName,
+		summary: "Track who wins poker games, in " + bootstrap.DBFileName + " or on a poker server.\n\n" +
+			"Exits with 0 on success, 1 if the command failed and 2 if it was used wrongly.",
+		subcommands: []*command{
+			playCommand(),
+			leagueCommand(),
+			scoreCommand(),
+			leaderboardCommand(),
+			batchCommand(),
+			auditCommand(),
+			exportCommand(),
+			importCommand(),
+			backupCommand(),
+			restoreCommand(),
+			rekeyCommand(),
+			seasonCommand(),
+			bankCommand(),
+			serveCommand(),
+			completionCommand(),
+			completeCommand(),
+		},
+	}
+}
+
+func playCommand() *command {
+	return &command{
+		name:    "play",
+		summary: "Type {name} wins to record a win, score {name} to see their wins or league to see everyone's.",
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			store := addStoreFlags(flags)
+			return func([]string) error { return play(store) }
+		},
+	}
+}
+
+// play reads one command from stdin. Wins are audited by the store they are recorded in, on
+// behalf of the current user.
+func play(flags *storeFlags) error {
+	store, close, err := flags.open()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	fmt.Println("Let's play poker")
+	fmt.Println("Type {name} wins to record a win, score {name} to see their wins or league to see everyone's")
+
+	return poker.NewCLI(store, os.Stdin, poker.WithCLIOutput(os.Stdout)).PlayPoker()
+}
+
+func leagueCommand() *command {
+	return &command{
+		name:    "league",
+		summary: "Print every player, most wins first.",
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			store := addStoreFlags(flags)
+			return func([]string) error { return printLeague(store) }
+		},
+	}
+}
+
+func printLeague(flags *storeFlags) error {
+	store, close, err := flags.open()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	league, err := store.GetLeague(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tPLAYER\tWINS")
+
+	for i, p := range league {
+		fmt.Fprintf(w, "%d\t%s\t%d\n", i+1, p.Name, p.Wins)
+	}
+
+	return w.Flush()
+}
+
+func scoreCommand() *command {
+	return &command{
+		name:    "score",
+		args:    "NAME",
+		summary: "Print how many games a player has won.",
+		minArgs: 1,
+		maxArgs: -1,
+		players: true,
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			store := addStoreFlags(flags)
+			return func(args []string) error { return printScore(store, strings.Join(args, " ")) }
+		},
+	}
+}
+
+func printScore(flags *storeFlags, name string) error {
+	store, close, err := flags.open()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	score, err := store.GetPlayerScore(context.Background(), name)
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(score)
+	return nil
+}
+
+func auditCommand() *command {
+	return &command{
+		name:    "audit",
+		args:    "[PLAYER]",
+		summary: "Print every change made to the league, optionally only those to one player.",
+		maxArgs: 1,
+		players: true,
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			return printAudit
+		},
+	}
+}
+
+// printAudit writes the audit log, optionally filtered to the player named in args.
+func printAudit(args []string) error {
+	auditLog, closeAudit, err := bootstrap.OpenAuditLog()
+
+	if err != nil {
+		return err
+	}
+
+	defer closeAudit()
+
+	player := ""
+
+	if len(args) > 0 {
+		player = args[0]
+	}
+
+	entries, err := auditLog.Entries(player)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tACTION\tPLAYER\tDETAIL\tACTOR\tSOURCE\tREQUEST ID")
+
+	for _, e := range entries {
+		actor := e.Actor
+
+		if e.ClaimedActor != "" {
+			actor = fmt.Sprintf("%s (claims to be %s)", e.Actor, e.ClaimedActor)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Format(time.RFC3339), e.Action, e.Player, e.Detail, actor, e.Source, e.RequestID)
+	}
+
+	return w.Flush()
+}
+
+// storeFlags say which league a command works on: the one on a poker server, or else the
+// one bootstrap.Config says is kept locally.
+type storeFlags struct {
+	server string
+	config bootstrap.Config
+}
+
+// addStoreFlags adds -server and the store flags of bootstrap.Config to a command that opens the league.
+func addStoreFlags(flags *flag.FlagSet) *storeFlags {
+	s := &storeFlags{}
+	flags.StringVar(&s.server, "server", "", "use the poker server at this url, e.g. http://host:4000, instead of "+bootstrap.DBFileName)
+	s.config.RegisterStoreFlags(flags)
+	return s
+}
+
+// open opens the poker server, acting as the current user, or the store the config says if there is no server.
+func (s *storeFlags) open() (poker.PlayerStore, func(), error) {
+	if s.server == "" {
+		return s.config.OpenStore()
+	}
+
+	return openServer(s.server)
+}
+
+// openServer opens the poker server at url, acting as the current user.
+func openServer(url string) (poker.PlayerStore, func(), error) {
+	c, err := client.New(url, client.WithActor(bootstrap.CurrentUser()))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return client.NewPlayerStore(c), func() {}, nil
+}
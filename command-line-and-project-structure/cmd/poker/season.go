@@ -0,0 +1,102 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+func seasonCommand() *command {
+	return &command{
+		name:    "season",
+		summary: "Print the current and archived seasons.",
+		setup: func(*flag.FlagSet) func([]string) error {
+			return func([]string) error { return printSeasons() }
+		},
+		subcommands: []*command{
+			{
+				name:    "new",
+				summary: "Archive the current season and start the next one with an empty league.",
+				setup: func(*flag.FlagSet) func([]string) error {
+					return func([]string) error { return newSeason() }
+				},
+			},
+		},
+	}
+}
+
+// newSeason archives the current season and starts the next one with an empty league.
+func newSeason() error {
+	store, close, err := bootstrap.OpenFileStore()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	archived, err := store.NewSeason(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("archived season %d, season %d has started\n", archived.Number, archived.Number+1)
+	return nil
+}
+
+func printSeasons() error {
+	store, close, err := bootstrap.OpenFileStore()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	ctx := context.Background()
+
+	current, err := store.CurrentSeason(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	seasons, err := store.Seasons(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEASON\tSTARTED\tENDED\tWINNER")
+
+	for _, s := range seasons {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Number, formatSeasonTime(s.Started), formatSeasonTime(s.Ended), seasonWinner(s.League))
+	}
+
+	fmt.Fprintf(w, "%d\t%s\t-\t-\n", current.Number, formatSeasonTime(current.Started))
+
+	return w.Flush()
+}
+
+func formatSeasonTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func seasonWinner(league poker.League) string {
+	if len(league) == 0 {
+		return "-"
+	}
+
+	return league[0].Name
+}
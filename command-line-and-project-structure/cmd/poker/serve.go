@@ -0,0 +1,134 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// shutdownTimeout is how long serve waits for requests in flight once it is told to stop.
+const shutdownTimeout = 10 * time.Second
+
+func serveCommand() *command {
+	return &command{
+		name:    "serve",
+		summary: "Serve the league over HTTP or HTTPS until interrupted, with its audit log and tournaments.",
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			var config bootstrap.Config
+			addr := flags.String("addr", ":4000", "address to listen on")
+			config.RegisterFlags(flags)
+			return func([]string) error { return serve(config, *addr) }
+		},
+	}
+}
+
+// serve serves the league on addr until interrupted, then waits for requests in flight and
+// closes the league, so queued webhooks are sent or dead-lettered and nothing is left half written.
+func serve(config bootstrap.Config, addr string) error {
+	var tlsConfig *tls.Config
+
+	if err := config.TLS.Validate(); err != nil {
+		return usagef("%v", err)
+	}
+
+	if config.TLS.Enabled() {
+		var err error
+
+		if tlsConfig, err = config.TLS.Load(); err != nil {
+			return err
+		}
+	}
+
+	handler, closeServer, err := config.NewServer()
+
+	if err != nil {
+		return err
+	}
+
+	defer closeServer()
+
+	listener, err := net.Listen("tcp", addr)
+
+	if err != nil {
+		return fmt.Errorf("could not listen on %s, %v", addr, err)
+	}
+
+	servers := []*http.Server{{Handler: handler}}
+	listeners := []net.Listener{listener}
+	scheme := "http"
+
+	if tlsConfig != nil {
+		listeners[0] = tls.NewListener(listener, tlsConfig)
+		scheme = "https"
+	}
+
+	if config.TLS.RedirectAddr != "" {
+		redirectListener, err := net.Listen("tcp", config.TLS.RedirectAddr)
+
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("could not listen on %s, %v", config.TLS.RedirectAddr, err)
+		}
+
+		servers = append(servers, &http.Server{Handler: poker.RedirectToHTTPS(listener.Addr().String())})
+		listeners = append(listeners, redirectListener)
+		fmt.Printf("redirecting http://%s to https\n", redirectListener.Addr())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		for _, server := range servers {
+			server.Shutdown(shutdownCtx)
+		}
+	}()
+
+	fmt.Printf("serving the league on %s://%s\n", scheme, listener.Addr())
+
+	errs := make(chan error, len(servers))
+
+	for i := range servers {
+		go func(server *http.Server, listener net.Listener) {
+			err := server.Serve(listener)
+
+			if !errors.Is(err, http.ErrServerClosed) {
+				err = fmt.Errorf("could not serve on %s, %v", listener.Addr(), err)
+				stop()
+			} else {
+				err = nil
+			}
+
+			errs <- err
+		}(servers[i], listeners[i])
+	}
+
+	var serveErr error
+
+	for range servers {
+		if err := <-errs; err != nil && serveErr == nil {
+			serveErr = err
+		}
+	}
+
+	<-stopped
+	return serveErr
+}
@@ -0,0 +1,10 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package main
+
+import "os"
+
+// ttyWidth never knows the width of the terminal where it cannot ask for it.
+func ttyWidth(f *os.File) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,27 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// winsize is the window size the TIOCGWINSZ ioctl reports.
+type winsize struct {
+	rows, columns, xPixels, yPixels uint16
+}
+
+// ttyWidth asks the terminal f is attached to how many columns it has.
+func ttyWidth(f *os.File) (int, bool) {
+	var size winsize
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&size)))
+
+	if errno != 0 || size.columns == 0 {
+		return 0, false
+	}
+
+	return int(size.columns), true
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/bootstrap"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func exportCommand() *command {
+	return &command{
+		name:    "export",
+		summary: "Write the league to stdout.",
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			format := flags.String("format", poker.FormatJSON, "output format, json or csv")
+			return func([]string) error { return export(*format) }
+		},
+	}
+}
+
+func export(format string) error {
+	store, close, err := bootstrap.OpenFileStore()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	league, err := store.GetLeague(context.Background())
+
+	if err != nil {
+		return err
+	}
+
+	return poker.ExportLeague(os.Stdout, league, format)
+}
+
+func importCommand() *command {
+	return &command{
+		name:    "import",
+		args:    "FILE",
+		summary: "Merge the league in FILE into the local one, printing what changes.",
+		minArgs: 1,
+		maxArgs: 1,
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			format := flags.String("format", poker.FormatJSON, "input format, json or csv")
+			replace := flags.Bool("replace", false, "replace the league instead of merging into it")
+			dryRun := flags.Bool("dry-run", false, "show the changes without writing them")
+			return func(args []string) error { return importLeague(args[0], *format, *replace, *dryRun) }
+		},
+	}
+}
+
+func importLeague(path, format string, replace, dryRun bool) error {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	incoming, err := poker.ImportLeague(file, format)
+
+	if err != nil {
+		return err
+	}
+
+	store, close, err := bootstrap.OpenFileStore()
+
+	if err != nil {
+		return err
+	}
+
+	defer close()
+
+	ctx := context.Background()
+	var changes []poker.LeagueChange
+
+	if dryRun {
+		changes, err = previewImport(ctx, store, incoming, replace)
+	} else {
+		changes, err = store.MergeLeague(ctx, incoming, replace)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		fmt.Printf("%s: %d -> %d\n", change.Name, change.Before, change.After)
+	}
+
+	return nil
+}
+
+// previewImport returns the changes importing incoming would make, without making them.
+func previewImport(ctx context.Context, store poker.PlayerStore, incoming poker.League, replace bool) ([]poker.LeagueChange, error) {
+	current, err := store.GetLeague(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := incoming
+
+	if !replace {
+		result = poker.MergeLeagues(current, incoming)
+	}
+
+	return poker.DiffLeagues(current, result), nil
+}
+
+func backupCommand() *command {
+	return &command{
+		name:    "backup",
+		summary: "Snapshot the local database, printing where the snapshot was written.",
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			dir := flags.String("dir", "backups", "directory to write the snapshot to")
+			return func([]string) error { return backup(*dir) }
+		},
+	}
+}
+
+func backup(dir string) error {
+	path, err := poker.Backup(bootstrap.DBFileName, dir, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(path)
+	return nil
+}
+
+func restoreCommand() *command {
+	return &command{
+		name:    "restore",
+		args:    "BACKUP",
+		summary: "Replace the local database with a snapshot taken by backup.",
+		minArgs: 1,
+		maxArgs: 1,
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			return func(args []string) error { return bootstrap.Restore(context.Background(), args[0]) }
+		},
+	}
+}
+
+func rekeyCommand() *command {
+	return &command{
+		name:    "rekey",
+		args:    "[NEW_KEY_FILE]",
+		summary: "Encrypt the local database, audit log and tournaments with the key in NEW_KEY_FILE, generating one if it doesn't exist.",
+		maxArgs: 1,
+		setup: func(flags *flag.FlagSet) func([]string) error {
+			decrypt := flags.Bool("decrypt", false, "store them unencrypted instead")
+			return func(args []string) error {
+				if len(args) != 1 && !*decrypt {
+					return usagef("give NEW_KEY_FILE or -decrypt")
+				}
+
+				path := ""
+
+				if !*decrypt {
+					path = args[0]
+				}
+
+				return rekey(path)
+			}
+		},
+	}
+}
+
+// rekey encrypts the league, audit log and tournaments with the key in path, or decrypts them if path is empty.
+func rekey(path string) error {
+	var key []byte
+
+	if path != "" {
+		var err error
+
+		if key, err = newKey(path); err != nil {
+			return err
+		}
+	}
+
+	if err := bootstrap.Rekey(context.Background(), key); err != nil {
+		return err
+	}
+
+	if path == "" {
+		fmt.Println("league decrypted, unset", poker.EncryptionKeyEnv, "and", poker.EncryptionKeyFileEnv)
+	} else {
+		fmt.Printf("league re-encrypted, set %s=%s\n", poker.EncryptionKeyFileEnv, path)
+	}
+
+	return nil
+}
+
+// newKey reads the key in path, generating one there if the file does not exist yet.
+func newKey(path string) ([]byte, error) {
+	if _, err := os.Stat(path); err == nil {
+		return poker.ReadEncryptionKeyFile(path)
+	}
+
+	key, err := poker.GenerateEncryptionKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key, poker.WriteEncryptionKeyFile(path, key)
+}
@@ -0,0 +1,66 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Actions a Command can take.
+const (
+	CommandWins   = "wins"
+	CommandScore  = "score"
+	CommandLeague = "league"
+)
+
+// ErrUnknownCommand is returned when input is not a command.
+var ErrUnknownCommand = errors.New(`unknown command, try "{name} wins", "score {name}" or "league"`)
+
+// Command is a line of input typed at the command line or in chat.
+type Command struct {
+	Action string
+	Player string
+}
+
+// ParseCommand reads "{name} wins", "score {name}" or "league" from input.
+func ParseCommand(input string) (Command, error) {
+	fields := strings.Fields(input)
+
+	switch {
+	case len(fields) == 1 && strings.EqualFold(fields[0], CommandLeague):
+		return Command{Action: CommandLeague}, nil
+	case len(fields) > 1 && strings.EqualFold(fields[0], CommandScore):
+		return Command{Action: CommandScore, Player: strings.Join(fields[1:], " ")}, nil
+	case len(fields) > 1 && strings.EqualFold(fields[len(fields)-1], CommandWins):
+		return Command{Action: CommandWins, Player: strings.Join(fields[:len(fields)-1], " ")}, nil
+	}
+
+	return Command{}, ErrUnknownCommand
+}
+
+func formatWin(player string) string {
+	return fmt.Sprintf("Recorded a win for %s", player)
+}
+
+func formatScore(player string, score int) string {
+	if score == 1 {
+		return fmt.Sprintf("%s has won 1 game", player)
+	}
+
+	return fmt.Sprintf("%s has won %d games", player, score)
+}
+
+// formatLeague lists the league one player to a line, most wins first.
+func formatLeague(league League) string {
+	if len(league) == 0 {
+		return "Nobody has won a game yet"
+	}
+
+	var lines strings.Builder
+
+	for i, player := range league {
+		fmt.Fprintf(&lines, "%d. %s %d\n", i+1, player.Name, player.Wins)
+	}
+
+	return strings.TrimSuffix(lines.String(), "\n")
+}
@@ -0,0 +1,208 @@
+package poker
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers chat platforms sign slash command requests with.
+const (
+	CommandSignatureHeader = "X-Slack-Signature"
+	CommandTimestampHeader = "X-Slack-Request-Timestamp"
+)
+
+// Where a CommandResponse is shown: to everyone in the channel, or only to whoever typed the command.
+const (
+	CommandResponseInChannel = "in_channel"
+	CommandResponseEphemeral = "ephemeral"
+)
+
+// commandMaxAge is how old a signed request can be before it is treated as a replay.
+const commandMaxAge = 5 * time.Minute
+
+const maxCommandSize = 1 << 16
+
+// CommandResponse is the reply to a slash command.
+type CommandResponse struct {
+	ResponseType string              `json:"response_type"`
+	Text         string              `json:"text"`
+	Attachments  []CommandAttachment `json:"attachments,omitempty"`
+}
+
+// CommandAttachment is a block of text shown under a CommandResponse.
+type CommandAttachment struct {
+	Text string `json:"text"`
+}
+
+var (
+	errCommandUnsigned     = errors.New("the request is not signed")
+	errCommandBadSignature = errors.New("the request signature does not match")
+	errCommandExpired      = errors.New("the request timestamp is too old or in the future")
+	errCommandReplayed     = errors.New("the request has already been received")
+)
+
+// SignCommand returns the signature header value for a slash command body sent at
+// timestamp: the hex HMAC-SHA256 of "v0:{timestamp}:{body}" keyed with secret, prefixed with "v0=".
+func SignCommand(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// commandsHandler runs a slash command such as "/poker Chris wins" posted as a form.
+// The text field holds the command, and user_name who typed it.
+func (p *PlayerServer) commandsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCommandSize))
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not read command, %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := p.verifyCommand(r.Header, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not parse command, %v", err), http.StatusBadRequest)
+		return
+	}
+
+	command, err := ParseCommand(form.Get("text"))
+
+	if err != nil {
+		writeJSON(w, http.StatusOK, CommandResponse{ResponseType: CommandResponseEphemeral, Text: err.Error()})
+		return
+	}
+
+	switch command.Action {
+	case CommandScore:
+		score, err := p.store.GetPlayerScore(r.Context(), command.Player)
+
+		if err != nil {
+			storeError(w, fmt.Sprintf("could not get score for %s", command.Player), err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, CommandResponse{ResponseType: CommandResponseInChannel, Text: formatScore(command.Player, score)})
+	case CommandLeague:
+		league, err := p.store.GetLeague(r.Context())
+
+		if err != nil {
+			storeError(w, "could not get league", err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, CommandResponse{
+			ResponseType: CommandResponseInChannel,
+			Text:         "League",
+			Attachments:  []CommandAttachment{{Text: "```\n" + formatLeague(league) + "\n```"}},
+		})
+	default:
+		ctx := commandContext(r.Context(), form)
+
+		if err := p.store.RecordWin(ctx, command.Player); err != nil {
+			storeError(w, fmt.Sprintf("could not record win for %s", command.Player), err)
+			return
+		}
+
+		p.winRecorded(ctx, command.Player)
+		writeJSON(w, http.StatusOK, CommandResponse{ResponseType: CommandResponseInChannel, Text: formatWin(command.Player)})
+	}
+}
+
+// commandContext audits changes made by a command on behalf of the chat user who typed it. The
+// user comes from a signed request, so unlike an X-Actor header it can be trusted.
+func commandContext(ctx context.Context, form url.Values) context.Context {
+	origin, _ := OriginFromContext(ctx)
+
+	if user := form.Get("user_name"); user != "" {
+		origin.Actor = user
+		origin.ClaimedActor = ""
+	}
+
+	return ContextWithOrigin(ctx, origin)
+}
+
+// verifyCommand checks body was signed with the signing secret recently enough not to be a replay,
+// and that the same signed request has not been received before.
+func (p *PlayerServer) verifyCommand(header http.Header, body []byte) error {
+	timestamp, signature := header.Get(CommandTimestampHeader), header.Get(CommandSignatureHeader)
+
+	if timestamp == "" || signature == "" {
+		return errCommandUnsigned
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return errCommandExpired
+	}
+
+	sent := time.Unix(seconds, 0)
+	age := p.now().Sub(sent)
+
+	if age > commandMaxAge || age < -commandMaxAge {
+		return errCommandExpired
+	}
+
+	if !hmac.Equal([]byte(SignCommand(p.commands, timestamp, body)), []byte(signature)) {
+		return errCommandBadSignature
+	}
+
+	if !p.replays.first(signature, sent.Add(commandMaxAge), p.now()) {
+		return errCommandReplayed
+	}
+
+	return nil
+}
+
+// seenCommands remembers the signatures of the commands received until they are too old to be
+// accepted anyway, so each signed request is only run once.
+type seenCommands struct {
+	mu         sync.Mutex
+	signatures map[string]time.Time
+}
+
+// first reports whether signature has not been seen before, remembering it until expires.
+func (s *seenCommands) first(signature string, expires, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.signatures == nil {
+		s.signatures = map[string]time.Time{}
+	}
+
+	for seen, until := range s.signatures {
+		if now.After(until) {
+			delete(s.signatures, seen)
+		}
+	}
+
+	if _, ok := s.signatures[signature]; ok {
+		return false
+	}
+
+	s.signatures[signature] = expires
+	return true
+}
@@ -0,0 +1,201 @@
+package poker
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var commandSecret = []byte("8f742231b10e8888abcd99yyyzzz85a5")
+
+func TestCommandEndpoint(t *testing.T) {
+	newServer := func(t *testing.T) (*PlayerServer, *StubPlayerStore) {
+		store := &StubPlayerStore{scores: map[string]int{"Cleo": 3}}
+		return NewPlayerServer(store, WithCommands(commandSecret)), store
+	}
+
+	t.Run("records a win", func(t *testing.T) {
+		server, store := newServer(t)
+
+		response := serve(server, newCommandRequest("Chris wins", true))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+		assertCommandResponse(t, response.Body, CommandResponse{ResponseType: CommandResponseInChannel, Text: "Recorded a win for Chris"})
+		AssertPlayerWin(t, store, "Chris")
+	})
+
+	t.Run("audits the win on behalf of whoever typed it", func(t *testing.T) {
+		store, auditLog := newAuditedStore(t, `[]`)
+		server := NewPlayerServer(store, WithCommands(commandSecret))
+
+		request := newCommandRequest("Chris wins", true)
+		request.Header.Set("X-Actor", "mallory")
+		serve(server, request)
+
+		entries, _ := auditLog.Entries("Chris")
+
+		if len(entries) != 1 || entries[0].Actor != "ruth" || entries[0].ClaimedActor != "" {
+			t.Errorf("unexpected audit entries %+v", entries)
+		}
+	})
+
+	t.Run("replies with a score", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		response := serve(server, newCommandRequest("score Cleo", true))
+
+		assertCommandResponse(t, response.Body, CommandResponse{ResponseType: CommandResponseInChannel, Text: "Cleo has won 3 games"})
+	})
+
+	t.Run("replies with the league as an attachment", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		response := serve(server, newCommandRequest("league", true))
+
+		assertCommandResponse(t, response.Body, CommandResponse{
+			ResponseType: CommandResponseInChannel,
+			Text:         "League",
+			Attachments:  []CommandAttachment{{Text: "```\n1. Cleo 3\n```"}},
+		})
+	})
+
+	t.Run("explains unknown commands only to whoever typed them", func(t *testing.T) {
+		server, store := newServer(t)
+
+		response := serve(server, newCommandRequest("deal", true))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertCommandResponse(t, response.Body, CommandResponse{ResponseType: CommandResponseEphemeral, Text: ErrUnknownCommand.Error()})
+
+		if len(store.winCalls) != 0 {
+			t.Errorf("recorded wins %v for an unknown command", store.winCalls)
+		}
+	})
+
+	t.Run("runs a signed request only once", func(t *testing.T) {
+		server, store := newServer(t)
+		request := newCommandRequest("Chris wins", true)
+		body := commandForm("Chris wins")
+
+		response := serve(server, request)
+		assertStatus(t, response.Code, http.StatusOK)
+
+		replay := httptest.NewRequest(http.MethodPost, "/commands", strings.NewReader(body))
+		replay.Header = request.Header.Clone()
+
+		response = serve(server, replay)
+		assertStatus(t, response.Code, http.StatusUnauthorized)
+
+		AssertPlayerWin(t, store, "Chris")
+	})
+
+	t.Run("forgets signatures once they are too old to be accepted", func(t *testing.T) {
+		var seen seenCommands
+		now := time.Now()
+
+		if !seen.first("v0=abc", now.Add(commandMaxAge), now) {
+			t.Fatal("expected a new signature to be accepted")
+		}
+
+		if seen.first("v0=abc", now.Add(commandMaxAge), now.Add(time.Minute)) {
+			t.Error("expected a repeated signature to be refused")
+		}
+
+		seen.first("v0=def", now.Add(2*commandMaxAge), now.Add(commandMaxAge+time.Second))
+
+		if _, ok := seen.signatures["v0=abc"]; ok {
+			t.Error("expected an expired signature to be forgotten")
+		}
+	})
+
+	t.Run("is not served without a signing secret", func(t *testing.T) {
+		server := NewPlayerServer(&StubPlayerStore{})
+
+		response := serve(server, newCommandRequest("league", true))
+
+		assertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	stale := newCommandRequest("Chris wins", false)
+	stale.Header.Set(CommandTimestampHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+	stale.Header.Set(CommandSignatureHeader, SignCommand(commandSecret, stale.Header.Get(CommandTimestampHeader), []byte(commandForm("Chris wins"))))
+
+	forged := newCommandRequest("Chris wins", true)
+	forged.Header.Set(CommandSignatureHeader, SignCommand([]byte("guess"), forged.Header.Get(CommandTimestampHeader), []byte(commandForm("Chris wins"))))
+
+	cases := []struct {
+		desc    string
+		request *http.Request
+	}{
+		{"an unsigned request", newCommandRequest("Chris wins", false)},
+		{"a request signed with another secret", forged},
+		{"a replayed request", stale},
+	}
+
+	for _, c := range cases {
+		t.Run("rejects "+c.desc, func(t *testing.T) {
+			server, store := newServer(t)
+
+			response := serve(server, c.request)
+
+			assertStatus(t, response.Code, http.StatusUnauthorized)
+
+			if len(store.winCalls) != 0 {
+				t.Errorf("recorded wins %v for %s", store.winCalls, c.desc)
+			}
+		})
+	}
+}
+
+func commandForm(text string) string {
+	return url.Values{"command": {"/poker"}, "text": {text}, "user_name": {"ruth"}}.Encode()
+}
+
+// newCommandRequest posts text as a slash command, signed with commandSecret if sign is set.
+func newCommandRequest(text string, sign bool) *http.Request {
+	if sign {
+		return newSignedCommandRequest(commandForm(text))
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/commands", strings.NewReader(commandForm(text)))
+	request.Header.Set("content-type", "application/x-www-form-urlencoded")
+	return request
+}
+
+func newSignedCommandRequest(body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/commands", strings.NewReader(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request.Header.Set("content-type", "application/x-www-form-urlencoded")
+	request.Header.Set(CommandTimestampHeader, timestamp)
+	request.Header.Set(CommandSignatureHeader, SignCommand(commandSecret, timestamp, []byte(body)))
+
+	return request
+}
+
+func assertCommandResponse(t testing.TB, body io.Reader, want CommandResponse) {
+	t.Helper()
+
+	var got CommandResponse
+
+	if err := json.NewDecoder(body).Decode(&got); err != nil {
+		t.Fatalf("could not parse command response, %v", err)
+	}
+
+	if got.ResponseType != want.ResponseType || got.Text != want.Text || len(got.Attachments) != len(want.Attachments) {
+		t.Fatalf("got response %+v want %+v", got, want)
+	}
+
+	for i := range want.Attachments {
+		if got.Attachments[i] != want.Attachments[i] {
+			t.Errorf("got attachment %q want %q", got.Attachments[i].Text, want.Attachments[i].Text)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package poker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	cases := []struct {
+		input string
+		want  Command
+	}{
+		{"Chris wins", Command{Action: CommandWins, Player: "Chris"}},
+		{"  Mary Ann   wins ", Command{Action: CommandWins, Player: "Mary Ann"}},
+		{"score Cleo", Command{Action: CommandScore, Player: "Cleo"}},
+		{"Score Cleo", Command{Action: CommandScore, Player: "Cleo"}},
+		{"league", Command{Action: CommandLeague}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			got, err := ParseCommand(c.input)
+
+			assertNoError(t, err)
+
+			if got != c.want {
+				t.Errorf("got %+v want %+v", got, c.want)
+			}
+		})
+	}
+
+	for _, input := range []string{"", "wins", "score", "league table", "Chris"} {
+		t.Run("rejects "+input, func(t *testing.T) {
+			if _, err := ParseCommand(input); !errors.Is(err, ErrUnknownCommand) {
+				t.Errorf("got error %v want %v", err, ErrUnknownCommand)
+			}
+		})
+	}
+}
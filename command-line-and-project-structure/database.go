@@ -0,0 +1,186 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DatabaseVersion is the version of the on-disk format written by FileSystemPlayerStore.
+const DatabaseVersion = 5
+
+// database is the versioned envelope FileSystemPlayerStore writes to disk.
+// Players is the league of the current season, Wins every win ever recorded,
+// Seasons the archived seasons, Transactions the ledger of everyone's chips and Keys the
+// idempotency keys wins were recorded with, so a key is saved along with its win.
+type database struct {
+	Version       int                       `json:"version"`
+	Season        int                       `json:"season"`
+	SeasonStarted *time.Time                `json:"season_started,omitempty"`
+	Players       League                    `json:"players"`
+	Wins          []Win                     `json:"wins"`
+	Seasons       []Season                  `json:"seasons"`
+	Transactions  Ledger                    `json:"transactions"`
+	Keys          map[string]idempotencyKey `json:"idempotency_keys"`
+}
+
+// databaseV2 is the envelope before wins and seasons were kept.
+type databaseV2 struct {
+	Version int    `json:"version"`
+	Players League `json:"players"`
+}
+
+// DatabaseVersionError is returned when a database was written by a newer version of poker.
+type DatabaseVersionError struct {
+	Version int
+}
+
+func (e DatabaseVersionError) Error() string {
+	return fmt.Sprintf("database version %d is newer than supported version %d, please upgrade poker", e.Version, DatabaseVersion)
+}
+
+// migrations upgrade the raw database of the version they are keyed by to the next version.
+var migrations = map[int]func([]byte) ([]byte, error){
+	1: migrateBareLeague,
+	2: migrateToSeasons,
+	3: migrateToLedger,
+	4: migrateToIdempotencyKeys,
+}
+
+func newDatabase(league League) database {
+	return database{Version: DatabaseVersion, Season: 1, Players: league}.normalised()
+}
+
+// normalised replaces missing lists with empty ones.
+func (d database) normalised() database {
+	if d.Players == nil {
+		d.Players = League{}
+	}
+
+	if d.Wins == nil {
+		d.Wins = []Win{}
+	}
+
+	if d.Seasons == nil {
+		d.Seasons = []Season{}
+	}
+
+	if d.Transactions == nil {
+		d.Transactions = Ledger{}
+	}
+
+	if d.Keys == nil {
+		d.Keys = map[string]idempotencyKey{}
+	}
+
+	return d
+}
+
+// loadDatabase reads a database of any supported version, reporting whether it had to be migrated.
+func loadDatabase(rdr io.Reader) (database, bool, error) {
+	data, err := io.ReadAll(rdr)
+
+	if err != nil {
+		return database{}, false, fmt.Errorf("problem reading database, %v", err)
+	}
+
+	version, err := databaseVersion(data)
+
+	if err != nil {
+		return database{}, false, err
+	}
+
+	if version > DatabaseVersion {
+		return database{}, false, DatabaseVersionError{version}
+	}
+
+	migrated := version < DatabaseVersion
+
+	for ; version < DatabaseVersion; version++ {
+		data, err = migrations[version](data)
+
+		if err != nil {
+			return database{}, false, fmt.Errorf("problem migrating database from version %d, %v", version, err)
+		}
+	}
+
+	var db database
+
+	if err := json.Unmarshal(data, &db); err != nil {
+		return database{}, false, fmt.Errorf("problem parsing database, %v", err)
+	}
+
+	return db.normalised(), migrated, nil
+}
+
+// databaseVersion detects the format of data; version 1 was a bare JSON array of players.
+func databaseVersion(data []byte) (int, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return 1, nil
+	}
+
+	var envelope struct {
+		Version int `json:"version"`
+	}
+
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return 0, fmt.Errorf("problem parsing database version, %v", err)
+	}
+
+	if envelope.Version < 2 {
+		return 0, fmt.Errorf("database has no valid version, got %d", envelope.Version)
+	}
+
+	return envelope.Version, nil
+}
+
+func migrateBareLeague(data []byte) ([]byte, error) {
+	league, err := NewLeague(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(databaseV2{Version: 2, Players: league})
+}
+
+// migrateToSeasons starts the first season with the existing league. Wins recorded
+// before version 3 have no time, so they only count towards the season's league.
+func migrateToSeasons(data []byte) ([]byte, error) {
+	var db databaseV2
+
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(database{Version: 3, Season: 1, Players: db.Players}.normalised())
+}
+
+// migrateToLedger opens every player's account with no chips.
+func migrateToLedger(data []byte) ([]byte, error) {
+	var db database
+
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	db.Version = 4
+
+	return json.Marshal(db.normalised())
+}
+
+// migrateToIdempotencyKeys keeps idempotency keys in the database. Keys kept beside it by
+// earlier versions are not carried over, as they did not say which player they were used for.
+func migrateToIdempotencyKeys(data []byte) ([]byte, error) {
+	var db database
+
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	db.Version = 5
+
+	return json.Marshal(db.normalised())
+}
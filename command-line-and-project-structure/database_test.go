@@ -0,0 +1,102 @@
+package poker
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestDatabaseMigrations(t *testing.T) {
+	t.Run("migrates a bare league to the current version on open", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 10)
+
+		assertOnDisk(t, database.Name(), `{"version":5,"season":1,"players":[{"Name":"Cleo","Wins":10}],"wins":[],"seasons":[],"transactions":[],"idempotency_keys":{}}`)
+	})
+
+	t.Run("starts the first season from a version 2 league", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"version":2,"players":[{"Name":"Cleo","Wins":10}]}`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 10)
+
+		assertOnDisk(t, database.Name(), `{"version":5,"season":1,"players":[{"Name":"Cleo","Wins":10}],"wins":[],"seasons":[],"transactions":[],"idempotency_keys":{}}`)
+	})
+
+	t.Run("opens an empty ledger for a version 3 database", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"version":3,"season":2,"players":[],"wins":[],"seasons":[]}`)
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+		assertOnDisk(t, database.Name(), `{"version":5,"season":2,"players":[],"wins":[],"seasons":[],"transactions":[],"idempotency_keys":{}}`)
+	})
+
+	t.Run("keeps idempotency keys in a version 4 database", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"version":4,"season":1,"players":[],"wins":[],"seasons":[],"transactions":[]}`)
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+		assertOnDisk(t, database.Name(), `{"version":5,"season":1,"players":[],"wins":[],"seasons":[],"transactions":[],"idempotency_keys":{}}`)
+	})
+
+	t.Run("writes new databases in the current version", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+		assertOnDisk(t, database.Name(), `{"version":5,"season":1,"players":[],"wins":[],"seasons":[],"transactions":[],"idempotency_keys":{}}`)
+	})
+
+	t.Run("refuses databases from a newer version", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"version":99,"players":[]}`)
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database)
+
+		var versionErr DatabaseVersionError
+		if !errors.As(err, &versionErr) {
+			t.Fatalf("expected a DatabaseVersionError but got %v", err)
+		}
+
+		if versionErr.Version != 99 {
+			t.Errorf("got version %d want %d", versionErr.Version, 99)
+		}
+	})
+
+	t.Run("refuses databases without a version", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"players":[]}`)
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database)
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func assertFileContents(t testing.TB, file io.ReadSeeker, want string) {
+	t.Helper()
+
+	file.Seek(0, 0)
+	got, _ := io.ReadAll(file)
+
+	if string(got) != want {
+		t.Errorf("got file contents %q want %q", got, want)
+	}
+}
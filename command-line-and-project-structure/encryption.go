@@ -0,0 +1,216 @@
+package poker
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Environment variables the database encryption key is read from.
+const (
+	EncryptionKeyEnv     = "POKER_DB_KEY"
+	EncryptionKeyFileEnv = "POKER_DB_KEY_FILE"
+)
+
+// EncryptionKeySize is the length in bytes of a database encryption key (AES-256).
+const EncryptionKeySize = 32
+
+var (
+	// ErrWrongKey is returned when an encrypted database cannot be decrypted with the key given.
+	ErrWrongKey = errors.New("database could not be decrypted, wrong encryption key")
+
+	// ErrNoKey is returned when a database is encrypted but no key was given.
+	ErrNoKey = errors.New("database is encrypted, set " + EncryptionKeyEnv + " or " + EncryptionKeyFileEnv)
+)
+
+// encryptedMagic starts every encrypted database so it can be told apart from plain JSON.
+var encryptedMagic = []byte("POKERENC1\n")
+
+// encryptor seals databases with AES-GCM.
+type encryptor struct {
+	aead cipher.AEAD
+}
+
+func newEncryptor(key []byte) (*encryptor, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem creating cipher, %v", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem creating cipher, %v", err)
+	}
+
+	return &encryptor{aead}, nil
+}
+
+// encryptorFor returns an encryptor for key, or nil if key is nil.
+func encryptorFor(key []byte) (*encryptor, error) {
+	if key == nil {
+		return nil, nil
+	}
+
+	return newEncryptor(key)
+}
+
+func (e *encryptor) encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, e.aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("problem generating nonce, %v", err)
+	}
+
+	sealed := append(append([]byte{}, encryptedMagic...), nonce...)
+	return e.aead.Seal(sealed, nonce, plaintext, encryptedMagic), nil
+}
+
+func (e *encryptor) decrypt(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(data, encryptedMagic)
+
+	if len(data) < e.aead.NonceSize() {
+		return nil, ErrWrongKey
+	}
+
+	nonce, ciphertext := data[:e.aead.NonceSize()], data[e.aead.NonceSize():]
+	plaintext, err := e.aead.Open(nil, nonce, ciphertext, encryptedMagic)
+
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+
+	return plaintext, nil
+}
+
+func isEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedMagic)
+}
+
+// decryptDatabase returns the plain database in data, decrypting it with encryptor if it is
+// encrypted. It fails with ErrNoKey if data is encrypted and there is no encryptor.
+func decryptDatabase(data []byte, encryptor *encryptor) ([]byte, error) {
+	if !isEncrypted(data) {
+		return data, nil
+	}
+
+	if encryptor == nil {
+		return nil, ErrNoKey
+	}
+
+	return encryptor.decrypt(data)
+}
+
+// encryptLine returns line sealed with encryptor and base64 encoded, so it is still one line
+// of a JSON lines file, or line as it is if encryptor is nil.
+func encryptLine(line []byte, encryptor *encryptor) ([]byte, error) {
+	if encryptor == nil {
+		return line, nil
+	}
+
+	sealed, err := encryptor.encrypt(line)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decryptLine returns the plain JSON in a line of a JSON lines file, decrypting it with
+// encryptor if it was written by encryptLine. It fails with ErrNoKey if the line is encrypted
+// and there is no encryptor.
+func decryptLine(line []byte, encryptor *encryptor) ([]byte, error) {
+	if isPlainLine(line) {
+		return line, nil
+	}
+
+	if encryptor == nil {
+		return nil, ErrNoKey
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(string(line))
+
+	if err != nil || !isEncrypted(sealed) {
+		return nil, fmt.Errorf("line is neither JSON nor encrypted")
+	}
+
+	return encryptor.decrypt(sealed)
+}
+
+// isPlainLine reports whether line is a JSON object, rather than one sealed by encryptLine.
+func isPlainLine(line []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(line), []byte("{"))
+}
+
+// GenerateEncryptionKey returns a new random database encryption key.
+func GenerateEncryptionKey() ([]byte, error) {
+	key := make([]byte, EncryptionKeySize)
+
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("problem generating encryption key, %v", err)
+	}
+
+	return key, nil
+}
+
+// WriteEncryptionKeyFile saves key hex encoded to path, readable only by its owner.
+func WriteEncryptionKeyFile(path string, key []byte) error {
+	err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), 0600)
+
+	if err != nil {
+		return fmt.Errorf("problem writing key file %s, %v", path, err)
+	}
+
+	return nil
+}
+
+// ReadEncryptionKeyFile reads a hex encoded key from path.
+func ReadEncryptionKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem reading key file %s, %v", path, err)
+	}
+
+	return decodeKey(string(data))
+}
+
+// EncryptionKeyFromEnv returns the key set in the environment, or nil if the database is not encrypted.
+func EncryptionKeyFromEnv() ([]byte, error) {
+	if key := os.Getenv(EncryptionKeyEnv); key != "" {
+		return decodeKey(key)
+	}
+
+	if path := os.Getenv(EncryptionKeyFileEnv); path != "" {
+		return ReadEncryptionKeyFile(path)
+	}
+
+	return nil, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(encoded))
+
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be hex encoded, %v", err)
+	}
+
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes, got %d", EncryptionKeySize, len(key))
+	}
+
+	return key, nil
+}
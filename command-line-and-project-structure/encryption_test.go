@@ -0,0 +1,311 @@
+package poker
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptedFileSystemStore(t *testing.T) {
+	key := newTestKey(t)
+
+	t.Run("encrypts the league on disk", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		assertNoError(t, err)
+		recordWin(t, store, "Cleo")
+
+		onDisk, _ := os.ReadFile(database.Name())
+
+		if !isEncrypted(onDisk) || strings.Contains(string(onDisk), "Cleo") {
+			t.Errorf("expected the league to be encrypted on disk, got %q", onDisk)
+		}
+
+		store, err = NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		assertNoError(t, err)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 11)
+	})
+
+	t.Run("refuses the wrong key", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		assertNoError(t, err)
+
+		_, err = NewFileSystemPlayerStore(database, WithEncryptionKey(newTestKey(t)))
+
+		if !errors.Is(err, ErrWrongKey) {
+			t.Errorf("got %v want %v", err, ErrWrongKey)
+		}
+	})
+
+	t.Run("refuses to open without a key", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		_, err := NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		assertNoError(t, err)
+
+		_, err = NewFileSystemPlayerStore(database)
+
+		if !errors.Is(err, ErrNoKey) {
+			t.Errorf("got %v want %v", err, ErrNoKey)
+		}
+	})
+
+	t.Run("rekeys the database", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		assertNoError(t, err)
+
+		newKey := newTestKey(t)
+		assertNoError(t, store.Rekey(context.Background(), newKey))
+
+		_, err = NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		if !errors.Is(err, ErrWrongKey) {
+			t.Errorf("got %v want %v", err, ErrWrongKey)
+		}
+
+		store, err = NewFileSystemPlayerStore(database, WithEncryptionKey(newKey))
+		assertNoError(t, err)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 10)
+
+		assertNoError(t, store.Rekey(context.Background(), nil))
+
+		store, err = NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 10)
+	})
+}
+
+func TestEncryptedAuditLog(t *testing.T) {
+	key := newTestKey(t)
+
+	t.Run("encrypts entries on disk", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		auditLog, closeAudit, err := AuditLogFromFile(path, WithAuditEncryptionKey(key))
+		assertNoError(t, err)
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Cleo"}))
+		closeAudit()
+
+		assertNotOnDisk(t, path, "Cleo")
+
+		auditLog, closeAudit, err = AuditLogFromFile(path, WithAuditEncryptionKey(key))
+		assertNoError(t, err)
+		defer closeAudit()
+
+		entries, err := auditLog.Entries("")
+		assertNoError(t, err)
+		assertAuditPlayers(t, entries, "Cleo")
+	})
+
+	t.Run("encrypts plain entries on open", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		auditLog, closeAudit, err := AuditLogFromFile(path)
+		assertNoError(t, err)
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Chris"}))
+		closeAudit()
+
+		auditLog, closeAudit, err = AuditLogFromFile(path, WithAuditEncryptionKey(key))
+		assertNoError(t, err)
+		defer closeAudit()
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Cleo"}))
+
+		assertNotOnDisk(t, path, "Chris")
+
+		entries, err := auditLog.Entries("")
+		assertNoError(t, err)
+		assertAuditPlayers(t, entries, "Chris", "Cleo")
+	})
+
+	t.Run("refuses to read entries without the key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+		auditLog, closeAudit, err := AuditLogFromFile(path, WithAuditEncryptionKey(key))
+		assertNoError(t, err)
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Cleo"}))
+		closeAudit()
+
+		auditLog, closeAudit, err = AuditLogFromFile(path)
+		assertNoError(t, err)
+		defer closeAudit()
+
+		_, err = auditLog.Entries("")
+
+		if !errors.Is(err, ErrNoKey) {
+			t.Errorf("got %v want %v", err, ErrNoKey)
+		}
+
+		_, _, err = AuditLogFromFile(path, WithAuditEncryptionKey(newTestKey(t)))
+
+		if !errors.Is(err, ErrWrongKey) {
+			t.Errorf("got %v want %v", err, ErrWrongKey)
+		}
+	})
+
+	t.Run("rekeys entries written through another log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		newKey := newTestKey(t)
+
+		auditLog, closeAudit, err := AuditLogFromFile(path, WithAuditEncryptionKey(key))
+		assertNoError(t, err)
+		defer closeAudit()
+		assertNoError(t, auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Chris"}))
+
+		other, closeOther, err := AuditLogFromFile(path, WithAuditEncryptionKey(key))
+		assertNoError(t, err)
+		defer closeOther()
+		assertNoError(t, other.Rekey(newKey))
+		assertNoError(t, other.Append(AuditEntry{Action: AuditActionRekey}))
+
+		reopened, closeReopened, err := AuditLogFromFile(path, WithAuditEncryptionKey(newKey))
+		assertNoError(t, err)
+		defer closeReopened()
+
+		entries, err := reopened.Entries("")
+		assertNoError(t, err)
+		assertAuditActions(t, entries, AuditActionWin, AuditActionRekey)
+	})
+}
+
+func TestEncryptedTournaments(t *testing.T) {
+	key := newTestKey(t)
+
+	t.Run("encrypts a plain file on open", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+		_, err = tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"})
+		assertNoError(t, err)
+
+		tournaments, err = NewTournaments(file, WithTournamentsEncryptionKey(key))
+		assertNoError(t, err)
+		assertNotOnDisk(t, file.Name(), "Cleo")
+
+		tournaments, err = NewTournaments(file, WithTournamentsEncryptionKey(key))
+		assertNoError(t, err)
+
+		got, err := tournaments.List()
+		assertNoError(t, err)
+
+		if len(got) != 1 || got[0].Name != "October" {
+			t.Errorf("got tournaments %v want October", got)
+		}
+
+		_, err = NewTournaments(file)
+
+		if !errors.Is(err, ErrNoKey) {
+			t.Errorf("got %v want %v", err, ErrNoKey)
+		}
+	})
+
+	t.Run("rekeys the file", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+		newKey := newTestKey(t)
+
+		tournaments, err := NewTournaments(file, WithTournamentsEncryptionKey(key))
+		assertNoError(t, err)
+		_, err = tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"})
+		assertNoError(t, err)
+		assertNoError(t, tournaments.Rekey(newKey))
+
+		_, err = NewTournaments(file, WithTournamentsEncryptionKey(key))
+
+		if !errors.Is(err, ErrWrongKey) {
+			t.Errorf("got %v want %v", err, ErrWrongKey)
+		}
+
+		tournaments, err = NewTournaments(file, WithTournamentsEncryptionKey(newKey))
+		assertNoError(t, err)
+
+		got, err := tournaments.List()
+		assertNoError(t, err)
+
+		if len(got) != 1 {
+			t.Errorf("got %d tournaments want 1", len(got))
+		}
+	})
+}
+
+// assertNotOnDisk checks the file at path is encrypted, so secret does not appear in it.
+func assertNotOnDisk(t testing.TB, path, secret string) {
+	t.Helper()
+
+	onDisk, err := os.ReadFile(path)
+	assertNoError(t, err)
+
+	if strings.Contains(string(onDisk), secret) {
+		t.Errorf("expected %q to be encrypted on disk, got %q", secret, onDisk)
+	}
+}
+
+func TestEncryptionKeyFromEnv(t *testing.T) {
+	key := newTestKey(t)
+
+	t.Run("reads a hex key", func(t *testing.T) {
+		t.Setenv(EncryptionKeyEnv, hex.EncodeToString(key))
+
+		got, err := EncryptionKeyFromEnv()
+		assertNoError(t, err)
+
+		if string(got) != string(key) {
+			t.Errorf("got key %x want %x", got, key)
+		}
+	})
+
+	t.Run("reads a key file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "key")
+		assertNoError(t, WriteEncryptionKeyFile(path, key))
+		t.Setenv(EncryptionKeyEnv, "")
+		t.Setenv(EncryptionKeyFileEnv, path)
+
+		got, err := EncryptionKeyFromEnv()
+		assertNoError(t, err)
+
+		if string(got) != string(key) {
+			t.Errorf("got key %x want %x", got, key)
+		}
+	})
+
+	t.Run("rejects short keys", func(t *testing.T) {
+		t.Setenv(EncryptionKeyEnv, "abcd")
+
+		_, err := EncryptionKeyFromEnv()
+		assertError(t, err)
+	})
+
+	t.Run("returns no key when unset", func(t *testing.T) {
+		t.Setenv(EncryptionKeyEnv, "")
+		t.Setenv(EncryptionKeyFileEnv, "")
+
+		got, err := EncryptionKeyFromEnv()
+		assertNoError(t, err)
+
+		if got != nil {
+			t.Errorf("got key %x want none", got)
+		}
+	})
+}
+
+func newTestKey(t testing.TB) []byte {
+	t.Helper()
+	key, err := GenerateEncryptionKey()
+	assertNoError(t, err)
+	return key
+}
@@ -0,0 +1,24 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd || windows)
+
+package poker
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// lockFile fails where advisory file locks are unavailable, as processes sharing the database
+// would otherwise overwrite each other's changes.
+func lockFile(file *os.File, how lockType) error {
+	return fmt.Errorf("file locking is not supported on %s", runtime.GOOS)
+}
+
+func unlockFile(file *os.File) error {
+	return nil
+}
+
+// syncDir is a no-op where directories cannot be synced.
+func syncDir(path string) error {
+	return nil
+}
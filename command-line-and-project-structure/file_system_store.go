@@ -1,60 +1,240 @@
 package poker
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"sort"
+	"sync"
+	"time"
 )
 
+// DefaultIdempotencyWindow is how long idempotency keys are remembered unless configured otherwise.
+const DefaultIdempotencyWindow = 24 * time.Hour
+
 // FileSystemPlayerStore stores players in the filesystem.
+// A sibling lock file is held while the database is read or written so several processes
+// can share it, and the database is reloaded whenever another process has changed it.
 type FileSystemPlayerStore struct {
-	database *json.Encoder
-	league   League
+	mu        sync.Mutex
+	tape      *tape
+	lock      *os.File
+	seen      fileVersion
+	league    League
+	wins      []Win
+	season    Season
+	seasons   []Season
+	ledger    Ledger
+	keys      idempotencyKeys
+	encryptor *encryptor
+	audit     storeAudit
+	now       func() time.Time
+}
+
+// fileVersion identifies the contents of a file last read or written by the store.
+type fileVersion struct {
+	size    int64
+	modTime time.Time
+}
+
+// FileSystemPlayerStoreOption configures a FileSystemPlayerStore.
+type FileSystemPlayerStoreOption func(*FileSystemPlayerStore) error
+
+// WithIdempotencyWindow sets how long idempotency keys are remembered.
+func WithIdempotencyWindow(window time.Duration) FileSystemPlayerStoreOption {
+	return func(f *FileSystemPlayerStore) error {
+		f.keys.window = window
+		return nil
+	}
+}
+
+// WithEncryptionKey encrypts the database at rest with key, encrypting a plain database on open.
+// A nil key leaves the database unencrypted.
+func WithEncryptionKey(key []byte) FileSystemPlayerStoreOption {
+	return func(f *FileSystemPlayerStore) error {
+		if key == nil {
+			return nil
+		}
+
+		encryptor, err := newEncryptor(key)
+		f.encryptor = encryptor
+		return err
+	}
+}
+
+// WithStoreAuditLog records every change made to the league in auditLog, on behalf of the Origin in
+// the context of the change, or origin for changes made without one.
+func WithStoreAuditLog(auditLog *AuditLog, origin Origin) FileSystemPlayerStoreOption {
+	return func(f *FileSystemPlayerStore) error {
+		f.audit = storeAudit{auditLog, origin}
+		return nil
+	}
 }
 
-func FileSystemFileStoreFromFile(path string) (*FileSystemPlayerStore, func(), error) {
+func FileSystemFileStoreFromFile(path string, options ...FileSystemPlayerStoreOption) (*FileSystemPlayerStore, func(), error) {
 	db, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
 
 	if err != nil {
 		return nil, nil, fmt.Errorf("problem opening %v, %s", err, path)
 	}
 
+	store, err := NewFileSystemPlayerStore(db, options...)
+
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("problem creating file sistem player store, %v", err)
+	}
+
 	closeFunc := func() {
+		store.Close()
 		db.Close()
 	}
 
-	store, err := NewFileSystemPlayerStore(db)
+	return store, closeFunc, nil
+}
+
+// ReadLeague reads the league from the database at path without opening a store, so the database
+// is never created, migrated, locked or written. Stores replace the database atomically, so the
+// league read is always whole. Only the encryption key of options is used.
+func ReadLeague(path string, options ...FileSystemPlayerStoreOption) (League, error) {
+	f := &FileSystemPlayerStore{}
+
+	for _, option := range options {
+		if err := option(f); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("problem creating file sistem player store, %v", err)
+		return nil, fmt.Errorf("problem reading %s, %v", path, err)
 	}
 
-	return store, closeFunc, nil
+	if data, err = decryptDatabase(data, f.encryptor); err != nil {
+		return nil, err
+	}
+
+	db, _, err := loadDatabase(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, fmt.Errorf("problem loading league from %s, %w", path, err)
+	}
+
+	league := append(League{}, db.Players...)
+	sortLeague(league)
+	return league, nil
 }
 
 // NewFileSystemPlayerStore creates a FileSystemPlayerStore initialising the store if needed.
-func NewFileSystemPlayerStore(file *os.File) (*FileSystemPlayerStore, error) {
-
-	err := initialisePlayerDBFile(file)
+func NewFileSystemPlayerStore(file *os.File, options ...FileSystemPlayerStoreOption) (*FileSystemPlayerStore, error) {
+	lock, err := os.OpenFile(file.Name()+".lock", os.O_RDWR|os.O_CREATE, 0666)
 
 	if err != nil {
-		return nil, fmt.Errorf("problem initialising player db file, %v", err)
+		return nil, fmt.Errorf("problem opening lock file for %s, %v", file.Name(), err)
+	}
+
+	store := &FileSystemPlayerStore{
+		tape: newTape(file),
+		lock: lock,
+		keys: idempotencyKeys{window: DefaultIdempotencyWindow},
+		now:  time.Now,
+	}
+
+	for _, option := range options {
+		if err := option(store); err != nil {
+			store.Close()
+			return nil, err
+		}
 	}
 
-	league, err := NewLeague(file)
+	err = store.withLock(lockExclusive, func() error {
+		if _, err := store.tape.refresh(); err != nil {
+			return err
+		}
+
+		if err := initialisePlayerDBFile(store.tape.file); err != nil {
+			return fmt.Errorf("problem initialising player db file, %v", err)
+		}
+
+		migrated, err := store.load()
+
+		if err != nil {
+			return fmt.Errorf("problem loading player store from file %s, %w", store.tape.file.Name(), err)
+		}
+
+		if migrated {
+			return store.save()
+		}
+
+		return nil
+	})
 
 	if err != nil {
-		return nil, fmt.Errorf("problem loading player store from file %s, %v", file.Name(), err)
+		store.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Rekey re-encrypts the database with key, or stores it unencrypted if key is nil.
+func (f *FileSystemPlayerStore) Rekey(ctx context.Context, key []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var encryptor *encryptor
+
+	if key != nil {
+		var err error
+
+		if encryptor, err = newEncryptor(key); err != nil {
+			return err
+		}
 	}
 
-	return &FileSystemPlayerStore{
-		database: json.NewEncoder(&tape{file}),
-		league:   league,
-	}, nil
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.withLock(lockExclusive, func() error {
+		if err := f.reloadIfChanged(); err != nil {
+			return err
+		}
+
+		old := f.encryptor
+		f.encryptor = encryptor
+
+		if err := f.save(); err != nil {
+			f.encryptor = old
+			return err
+		}
+
+		detail := "encrypted with a new key"
+
+		if encryptor == nil {
+			detail = "stored unencrypted"
+		}
+
+		f.audit.record(ctx, f.now(), AuditActionRekey, "", detail)
+		return nil
+	})
+}
+
+// Close releases the files the store opened itself.
+func (f *FileSystemPlayerStore) Close() error {
+	f.tape.Close()
+	return f.lock.Close()
 }
 
 func initialisePlayerDBFile(file *os.File) error {
+	empty, _ := json.Marshal(newDatabase(nil))
+	return initialiseDBFile(file, string(empty))
+}
+
+func initialiseDBFile(file *os.File, empty string) error {
 	file.Seek(0, 0)
 
 	info, err := file.Stat()
@@ -64,7 +244,7 @@ func initialisePlayerDBFile(file *os.File) error {
 	}
 
 	if info.Size() == 0 {
-		file.Write([]byte("[]"))
+		file.Write([]byte(empty))
 		file.Seek(0, 0)
 	}
 
@@ -72,34 +252,483 @@ func initialisePlayerDBFile(file *os.File) error {
 }
 
 // GetLeague returns the scores of all the players.
-func (f *FileSystemPlayerStore) GetLeague() League {
-	sort.Slice(f.league, func(i, j int) bool {
-		return f.league[i].Wins > f.league[j].Wins
+func (f *FileSystemPlayerStore) GetLeague(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.withLock(lockShared, f.reloadIfChanged); err != nil {
+		return nil, err
+	}
+
+	league := append(League{}, f.league...)
+	sortLeague(league)
+	return league, nil
+}
+
+// GetLeagueBetween returns the league counting only wins at or after since and before until.
+func (f *FileSystemPlayerStore) GetLeagueBetween(ctx context.Context, since, until time.Time) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.withLock(lockShared, f.reloadIfChanged); err != nil {
+		return nil, err
+	}
+
+	return leagueBetween(f.wins, since, until), nil
+}
+
+// CurrentSeason returns the season in play.
+func (f *FileSystemPlayerStore) CurrentSeason(ctx context.Context) (Season, error) {
+	if err := ctx.Err(); err != nil {
+		return Season{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.withLock(lockShared, f.reloadIfChanged); err != nil {
+		return Season{}, err
+	}
+
+	return f.season, nil
+}
+
+// Seasons returns every archived season, oldest first.
+func (f *FileSystemPlayerStore) Seasons(ctx context.Context) ([]Season, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.withLock(lockShared, f.reloadIfChanged); err != nil {
+		return nil, err
+	}
+
+	return append([]Season{}, f.seasons...), nil
+}
+
+// NewSeason archives the current season with its league and starts the next one with an empty league.
+// The history of wins is kept, so windows of time can still span seasons.
+func (f *FileSystemPlayerStore) NewSeason(ctx context.Context) (Season, error) {
+	if err := ctx.Err(); err != nil {
+		return Season{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var archived Season
+
+	err := f.update(func() error {
+		now := f.now().UTC()
+
+		archived = f.season
+		archived.Ended = now
+		archived.League = append(League{}, f.league...)
+		sortLeague(archived.League)
+
+		f.seasons = append(f.seasons, archived)
+		f.season = Season{Number: archived.Number + 1, Started: now}
+		f.league = League{}
+		return nil
 	})
-	return f.league
+
+	if err != nil {
+		return Season{}, err
+	}
+
+	f.audit.record(ctx, f.now(), AuditActionSeason, "", fmt.Sprintf("archived season %d", archived.Number))
+	return archived, nil
 }
 
 // GetPlayerScore retrieves a player's score.
-func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
+func (f *FileSystemPlayerStore) GetPlayerScore(ctx context.Context, name string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.withLock(lockShared, f.reloadIfChanged); err != nil {
+		return 0, err
+	}
 
 	player := f.league.Find(name)
 
 	if player != nil {
-		return player.Wins
+		return player.Wins, nil
 	}
 
-	return 0
+	return 0, nil
 }
 
 // RecordWin will store a win for a player, incrementing wins if already known.
-func (f *FileSystemPlayerStore) RecordWin(name string) {
-	player := f.league.Find(name)
+func (f *FileSystemPlayerStore) RecordWin(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	if player != nil {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := f.update(func() error {
+		f.recordWin(name, f.now().UTC())
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	f.audit.record(ctx, f.now(), AuditActionWin, name, "")
+	return nil
+}
+
+// RecordWins records a win for each name in a single save, so either all of them are kept or none.
+func (f *FileSystemPlayerStore) RecordWins(ctx context.Context, names []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	err := f.update(func() error {
+		now := f.now().UTC()
+
+		for _, name := range names {
+			f.recordWin(name, now)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		f.audit.record(ctx, f.now(), AuditActionWin, name, "")
+	}
+
+	return nil
+}
+
+func (f *FileSystemPlayerStore) recordWin(name string, at time.Time) {
+	if player := f.league.Find(name); player != nil {
 		player.Wins++
 	} else {
 		f.league = append(f.league, Player{name, 1})
 	}
 
-	f.database.Encode(f.league)
+	f.wins = append(f.wins, Win{name, at})
+}
+
+// ReplaceLeague overwrites every stored player with league. The wins of the current season only
+// change by the difference between the two leagues, so leagues over windows of time agree with it
+// and players it leaves alone keep the times of their wins.
+func (f *FileSystemPlayerStore) ReplaceLeague(ctx context.Context, league League) error {
+	_, err := f.MergeLeague(ctx, league, true)
+	return err
+}
+
+// MergeLeague merges incoming into the league as MergeLeagues does, or replaces the league with it
+// as ReplaceLeague does if replace is set, returning what changed. The league is read and saved under
+// one exclusive lock, so no win another process records in the meantime is overwritten.
+func (f *FileSystemPlayerStore) MergeLeague(ctx context.Context, incoming League, replace bool) ([]LeagueChange, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var changes []LeagueChange
+
+	err := f.update(func() error {
+		league := incoming
+
+		if !replace {
+			league = MergeLeagues(f.league, incoming)
+		}
+
+		changes = DiffLeagues(f.league, league)
+		f.wins = reconcileWins(f.wins, f.league, league, f.season.Started, f.now().UTC())
+		f.league = append(League{}, league...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	detail := fmt.Sprintf("replaced the league with %s", plural(len(incoming), "player"))
+
+	if !replace {
+		detail = fmt.Sprintf("merged %s into the league", plural(len(incoming), "player"))
+	}
+
+	f.audit.record(ctx, f.now(), AuditActionImport, "", detail)
+	return changes, nil
+}
+
+// RecordTransaction adds t to the ledger, failing with an InsufficientFundsError if it would overdraw the player
+// or an InsufficientPotError if it would pay out more of a game than was bought into it.
+func (f *FileSystemPlayerStore) RecordTransaction(ctx context.Context, t Transaction) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if t.Time.IsZero() {
+		t.Time = f.now().UTC()
+	}
+
+	err := f.update(func() error {
+		ledger, err := f.ledger.record(t)
+		f.ledger = ledger
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	f.audit.record(ctx, f.now(), AuditActionTransaction, t.Player, t.describe())
+	return nil
+}
+
+// GetAccount returns player's chip balance and transactions.
+func (f *FileSystemPlayerStore) GetAccount(ctx context.Context, player string) (Account, error) {
+	if err := ctx.Err(); err != nil {
+		return Account{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.withLock(lockShared, f.reloadIfChanged); err != nil {
+		return Account{}, err
+	}
+
+	return f.ledger.account(player), nil
+}
+
+// RecordWinOnce stores a win for a player unless key was already used within the idempotency window,
+// failing with ErrIdempotencyKeyReused if it was used for another player. The key is saved in the
+// database along with the win, so one is never remembered without the other.
+func (f *FileSystemPlayerStore) RecordWinOnce(ctx context.Context, name, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	recorded := false
+
+	err := f.withLock(lockExclusive, func() error {
+		if err := f.reloadIfChanged(); err != nil {
+			return err
+		}
+
+		now := f.now().UTC()
+
+		if used, ok := f.keys.used(key, now); ok {
+			if used.Player != name {
+				return ErrIdempotencyKeyReused
+			}
+
+			return nil
+		}
+
+		f.keys.seen[key] = idempotencyKey{name, now}
+		f.recordWin(name, now)
+
+		if err := f.save(); err != nil {
+			f.load()
+			return err
+		}
+
+		recorded = true
+		return nil
+	})
+
+	if recorded {
+		f.audit.record(ctx, f.now(), AuditActionWin, name, "")
+	}
+
+	return recorded, err
+}
+
+// update applies change to the latest league on disk and saves it while holding an exclusive lock.
+// Nothing is saved if change fails.
+func (f *FileSystemPlayerStore) update(change func() error) error {
+	return f.withLock(lockExclusive, func() error {
+		if err := f.reloadIfChanged(); err != nil {
+			return err
+		}
+
+		if err := change(); err != nil {
+			return err
+		}
+
+		if err := f.save(); err != nil {
+			f.load()
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (f *FileSystemPlayerStore) withLock(how lockType, fn func() error) error {
+	if err := lockFile(f.lock, how); err != nil {
+		return fmt.Errorf("problem locking %s, %v", f.lock.Name(), err)
+	}
+
+	defer unlockFile(f.lock)
+
+	return fn()
+}
+
+// reloadIfChanged reloads the league if another process has written to the file since it was last seen.
+func (f *FileSystemPlayerStore) reloadIfChanged() error {
+	replaced, err := f.tape.refresh()
+
+	if err != nil {
+		return err
+	}
+
+	version, err := f.version()
+
+	if err != nil || (!replaced && version == f.seen) {
+		return err
+	}
+
+	_, err = f.load()
+	return err
+}
+
+// load reads the database, reporting whether it needs saving again to bring
+// its format or encryption up to date.
+func (f *FileSystemPlayerStore) load() (bool, error) {
+	f.tape.file.Seek(0, 0)
+
+	data, err := io.ReadAll(f.tape.file)
+
+	if err != nil {
+		return false, fmt.Errorf("problem reading database, %v", err)
+	}
+
+	encrypted := isEncrypted(data)
+
+	if data, err = decryptDatabase(data, f.encryptor); err != nil {
+		return false, err
+	}
+
+	db, migrated, err := loadDatabase(bytes.NewReader(data))
+
+	if err != nil {
+		return false, err
+	}
+
+	f.league = db.Players
+	f.wins = db.Wins
+	f.season = Season{Number: db.Season}
+	f.seasons = db.Seasons
+	f.ledger = db.Transactions
+	f.keys.seen = db.Keys
+
+	if db.SeasonStarted != nil {
+		f.season.Started = *db.SeasonStarted
+	}
+
+	f.seen, err = f.version()
+
+	return migrated || encrypted != (f.encryptor != nil), err
+}
+
+func (f *FileSystemPlayerStore) save() error {
+	db := newDatabase(f.league)
+	db.Season = f.season.Number
+	db.Wins = f.wins
+	db.Seasons = f.seasons
+	db.Transactions = f.ledger
+	db.Keys = f.keys.seen
+
+	if !f.season.Started.IsZero() {
+		db.SeasonStarted = &f.season.Started
+	}
+
+	data, _ := json.Marshal(db.normalised())
+
+	if f.encryptor != nil {
+		var err error
+
+		if data, err = f.encryptor.encrypt(data); err != nil {
+			return fmt.Errorf("problem encrypting player store, %v", err)
+		}
+	}
+
+	if _, err := f.tape.Write(data); err != nil {
+		return fmt.Errorf("problem saving player store, %v", err)
+	}
+
+	var err error
+	f.seen, err = f.version()
+
+	return err
+}
+
+func (f *FileSystemPlayerStore) version() (fileVersion, error) {
+	return versionOf(f.tape.file.Name())
+}
+
+// versionOf returns the version of the file now at path.
+func versionOf(path string) (fileVersion, error) {
+	info, err := os.Stat(path)
+
+	if err != nil {
+		return fileVersion{}, fmt.Errorf("problem getting file info from file %s, %v", path, err)
+	}
+
+	return fileVersion{info.Size(), info.ModTime()}, nil
+}
+
+// idempotencyKeys remembers which player each idempotency key recorded a win for.
+type idempotencyKeys struct {
+	window time.Duration
+	seen   map[string]idempotencyKey
+}
+
+// idempotencyKey is the win an idempotency key was first used for.
+type idempotencyKey struct {
+	Player string    `json:"player"`
+	Time   time.Time `json:"time"`
+}
+
+// used returns the win key was used for within the window, forgetting keys that have expired.
+func (k *idempotencyKeys) used(key string, now time.Time) (idempotencyKey, bool) {
+	for seenKey, used := range k.seen {
+		if now.Sub(used.Time) >= k.window {
+			delete(k.seen, seenKey)
+		}
+	}
+
+	used, ok := k.seen[key]
+	return used, ok
 }
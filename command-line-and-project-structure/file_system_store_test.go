@@ -1,8 +1,14 @@
 package poker
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 )
 
 func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
@@ -19,6 +25,7 @@ func createTempFile(t testing.TB, initialData string) (*os.File, func()) {
 	removeFile := func() {
 		tmpfile.Close()
 		os.Remove(tmpfile.Name())
+		os.Remove(tmpfile.Name() + ".lock")
 	}
 
 	return tmpfile, removeFile
@@ -36,7 +43,7 @@ func TestFileSystemStore(t *testing.T) {
 
 		assertNoError(t, err)
 
-		got := store.GetLeague()
+		got := getLeague(t, store)
 
 		want := []Player{
 			{"Chris", 33},
@@ -46,7 +53,7 @@ func TestFileSystemStore(t *testing.T) {
 		assertLeague(t, got, want)
 
 		// read again
-		got = store.GetLeague()
+		got = getLeague(t, store)
 		assertLeague(t, got, want)
 	})
 
@@ -60,7 +67,7 @@ func TestFileSystemStore(t *testing.T) {
 
 		assertNoError(t, err)
 
-		got := store.GetPlayerScore("Chris")
+		got := getScore(t, store, "Chris")
 		want := 33
 		assertScoreEquals(t, got, want)
 	})
@@ -75,9 +82,9 @@ func TestFileSystemStore(t *testing.T) {
 
 		assertNoError(t, err)
 
-		store.RecordWin("Chris")
+		recordWin(t, store, "Chris")
 
-		got := store.GetPlayerScore("Chris")
+		got := getScore(t, store, "Chris")
 		want := 34
 		assertScoreEquals(t, got, want)
 	})
@@ -92,13 +99,217 @@ func TestFileSystemStore(t *testing.T) {
 
 		assertNoError(t, err)
 
-		store.RecordWin("Pepper")
+		recordWin(t, store, "Pepper")
 
-		got := store.GetPlayerScore("Pepper")
+		got := getScore(t, store, "Pepper")
 		want := 1
 		assertScoreEquals(t, got, want)
 	})
 
+	t.Run("store many wins at once", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		assertNoError(t, store.RecordWins(context.Background(), []string{"Cleo", "Chris", "Cleo"}))
+
+		store, err = NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		assertLeague(t, getLeague(t, store), []Player{{"Cleo", 12}, {"Chris", 1}})
+	})
+
+	t.Run("ignores retried wins with the same idempotency key", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		assertRecorded(t, recordWinOnce(t, store, "Cleo", "abc"), true)
+		assertRecorded(t, recordWinOnce(t, store, "Cleo", "abc"), false)
+
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 11)
+	})
+
+	t.Run("forgets idempotency keys after the window", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database, WithIdempotencyWindow(time.Minute))
+
+		assertNoError(t, err)
+
+		now := time.Now()
+		store.now = func() time.Time { return now }
+		recordWinOnce(t, store, "Cleo", "abc")
+
+		now = now.Add(time.Minute)
+		assertRecorded(t, recordWinOnce(t, store, "Cleo", "abc"), true)
+
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 2)
+	})
+
+	t.Run("remembers idempotency keys across restarts", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		recordWinOnce(t, store, "Cleo", "abc")
+
+		store, err = NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		assertRecorded(t, recordWinOnce(t, store, "Cleo", "abc"), false)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 1)
+	})
+
+	t.Run("saves idempotency keys in the database with their wins", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+		store.now = func() time.Time { return now }
+		recordWinOnce(t, store, "Cleo", "abc")
+
+		assertOnDisk(t, database.Name(), `{"version":5,"season":1,"players":[{"Name":"Cleo","Wins":1}],"wins":[{"player":"Cleo","time":"2026-10-01T12:00:00Z"}],"seasons":[],"transactions":[],"idempotency_keys":{"abc":{"player":"Cleo","time":"2026-10-01T12:00:00Z"}}}`)
+	})
+
+	t.Run("refuses an idempotency key used for another player", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		recordWinOnce(t, store, "Cleo", "abc")
+
+		if _, err := store.RecordWinOnce(context.Background(), "Chris", "abc"); !errors.Is(err, ErrIdempotencyKeyReused) {
+			t.Errorf("got error %v want %v", err, ErrIdempotencyKeyReused)
+		}
+
+		assertLeague(t, getLeague(t, store), []Player{{"Cleo", 1}})
+	})
+
+	t.Run("replaces the league", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		assertNoError(t, store.ReplaceLeague(context.Background(), League{{"Chris", 2}}))
+
+		store, err = NewFileSystemPlayerStore(database)
+
+		assertNoError(t, err)
+
+		assertLeague(t, getLeague(t, store), []Player{{"Chris", 2}})
+	})
+
+	t.Run("merges a league into the latest one on disk", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Chris", "Wins": 3}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		other, close, err := FileSystemFileStoreFromFile(database.Name())
+		assertNoError(t, err)
+		defer close()
+
+		recordWin(t, other, "Chris")
+
+		changes, err := store.MergeLeague(context.Background(), League{{"Cleo", 1}}, false)
+		assertNoError(t, err)
+
+		if want := []LeagueChange{{"Cleo", 0, 1}}; !reflect.DeepEqual(changes, want) {
+			t.Errorf("got changes %v want %v", changes, want)
+		}
+
+		assertLeague(t, getLeague(t, other), []Player{{"Chris", 4}, {"Cleo", 1}})
+	})
+
+	t.Run("shares the file with another process", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[
+			{"Name": "Cleo", "Wins": 10}]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		other, close, err := FileSystemFileStoreFromFile(database.Name())
+		assertNoError(t, err)
+		defer close()
+
+		recordWin(t, store, "Cleo")
+		recordWin(t, other, "Cleo")
+		recordWin(t, store, "Chris")
+
+		assertScoreEquals(t, getScore(t, other, "Cleo"), 12)
+		assertScoreEquals(t, getScore(t, other, "Chris"), 1)
+		assertLeague(t, getLeague(t, store), getLeague(t, other))
+	})
+
+	t.Run("shares idempotency keys with another process", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		stores := make([]*FileSystemPlayerStore, 4)
+
+		for i := range stores {
+			store, close, err := FileSystemFileStoreFromFile(database.Name())
+			assertNoError(t, err)
+			defer close()
+			stores[i] = store
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		recorded := 0
+
+		for _, store := range stores {
+			wg.Add(1)
+			go func(store *FileSystemPlayerStore) {
+				defer wg.Done()
+				ok, err := store.RecordWinOnce(context.Background(), "Cleo", "abc")
+				if err != nil {
+					t.Error(err)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if ok {
+					recorded++
+				}
+			}(store)
+		}
+
+		wg.Wait()
+
+		if recorded != 1 {
+			t.Errorf("got %d wins recorded with one key want 1", recorded)
+		}
+
+		assertScoreEquals(t, getScore(t, stores[0], "Cleo"), 1)
+	})
+
 	t.Run("works with an empty file", func(t *testing.T) {
 		database, cleanDatabase := createTempFile(t, "")
 		defer cleanDatabase()
@@ -109,6 +320,77 @@ func TestFileSystemStore(t *testing.T) {
 	})
 }
 
+func TestReadLeague(t *testing.T) {
+	t.Run("reads the league without writing the database", func(t *testing.T) {
+		original := `[{"Name": "Cleo", "Wins": 10}, {"Name": "Chris", "Wins": 33}]`
+		database, cleanDatabase := createTempFile(t, original)
+		defer cleanDatabase()
+
+		league, err := ReadLeague(database.Name())
+		assertNoError(t, err)
+		assertLeague(t, league, []Player{{"Chris", 33}, {"Cleo", 10}})
+
+		onDisk, _ := os.ReadFile(database.Name())
+
+		if string(onDisk) != original {
+			t.Errorf("database was rewritten to %q", onDisk)
+		}
+
+		if _, err := os.Stat(database.Name() + ".lock"); !os.IsNotExist(err) {
+			t.Errorf("expected no lock file beside the database, got %v", err)
+		}
+	})
+
+	t.Run("decrypts the league with the key", func(t *testing.T) {
+		key := newTestKey(t)
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database, WithEncryptionKey(key))
+		assertNoError(t, err)
+		recordWin(t, store, "Cleo")
+
+		league, err := ReadLeague(database.Name(), WithEncryptionKey(key))
+		assertNoError(t, err)
+		assertLeague(t, league, []Player{{"Cleo", 1}})
+
+		if _, err := ReadLeague(database.Name()); !errors.Is(err, ErrNoKey) {
+			t.Errorf("got error %v want %v", err, ErrNoKey)
+		}
+	})
+
+	t.Run("does not create a missing database", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db.json")
+
+		if _, err := ReadLeague(path); err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be created, got %v", path, err)
+		}
+	})
+}
+
+func getScore(t testing.TB, store PlayerStore, name string) int {
+	t.Helper()
+	score, err := store.GetPlayerScore(context.Background(), name)
+	assertNoError(t, err)
+	return score
+}
+
+func getLeague(t testing.TB, store PlayerStore) League {
+	t.Helper()
+	league, err := store.GetLeague(context.Background())
+	assertNoError(t, err)
+	return league
+}
+
+func recordWin(t testing.TB, store PlayerStore, name string) {
+	t.Helper()
+	assertNoError(t, store.RecordWin(context.Background(), name))
+}
+
 func assertScoreEquals(t testing.TB, got, want int) {
 	t.Helper()
 	if got != want {
@@ -116,6 +398,20 @@ func assertScoreEquals(t testing.TB, got, want int) {
 	}
 }
 
+func recordWinOnce(t testing.TB, store IdempotentPlayerStore, name, key string) bool {
+	t.Helper()
+	recorded, err := store.RecordWinOnce(context.Background(), name, key)
+	assertNoError(t, err)
+	return recorded
+}
+
+func assertRecorded(t testing.TB, got, want bool) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got recorded %t want %t", got, want)
+	}
+}
+
 func assertNoError(t testing.TB, err error) {
 	t.Helper()
 	if err != nil {
@@ -0,0 +1,35 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package poker
+
+import (
+	"os"
+	"syscall"
+)
+
+func lockFile(file *os.File, how lockType) error {
+	operation := syscall.LOCK_SH
+
+	if how == lockExclusive {
+		operation = syscall.LOCK_EX
+	}
+
+	return syscall.Flock(int(file.Fd()), operation)
+}
+
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
+
+// syncDir flushes a directory so a rename within it survives a crash.
+func syncDir(path string) error {
+	dir, err := os.Open(path)
+
+	if err != nil {
+		return err
+	}
+
+	defer dir.Close()
+
+	return dir.Sync()
+}
@@ -0,0 +1,55 @@
+//go:build windows
+
+package poker
+
+import (
+	"math"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procLockFileEx   = kernel32.NewProc("LockFileEx")
+	procUnlockFileEx = kernel32.NewProc("UnlockFileEx")
+)
+
+// lockfileExclusiveLock asks LockFileEx for an exclusive lock rather than a shared one.
+const lockfileExclusiveLock = 0x2
+
+// lockFile locks the whole of file with LockFileEx, waiting until it can.
+func lockFile(file *os.File, how lockType) error {
+	var flags uintptr
+
+	if how == lockExclusive {
+		flags = lockfileExclusiveLock
+	}
+
+	var overlapped syscall.Overlapped
+
+	ok, _, err := procLockFileEx.Call(file.Fd(), flags, 0, math.MaxUint32, math.MaxUint32, uintptr(unsafe.Pointer(&overlapped)))
+
+	if ok == 0 {
+		return err
+	}
+
+	return nil
+}
+
+func unlockFile(file *os.File) error {
+	var overlapped syscall.Overlapped
+
+	ok, _, err := procUnlockFileEx.Call(file.Fd(), 0, math.MaxUint32, math.MaxUint32, uintptr(unsafe.Pointer(&overlapped)))
+
+	if ok == 0 {
+		return err
+	}
+
+	return nil
+}
+
+// syncDir is a no-op, as directories cannot be synced on Windows.
+func syncDir(path string) error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package poker
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Win is a single win, kept so leagues can be worked out over any window of time.
+type Win struct {
+	Player string    `json:"player"`
+	Time   time.Time `json:"time"`
+}
+
+// Season is a numbered stretch of play. Archived seasons keep the league they ended with.
+type Season struct {
+	Number  int       `json:"number"`
+	Started time.Time `json:"started"`
+	Ended   time.Time `json:"ended"`
+	League  League    `json:"league"`
+}
+
+// HistoryPlayerStore is a PlayerStore that remembers when each win happened.
+type HistoryPlayerStore interface {
+	PlayerStore
+	// GetLeagueBetween returns the league counting only wins at or after since and before until.
+	// A zero since or until leaves that end of the window open.
+	GetLeagueBetween(ctx context.Context, since, until time.Time) (League, error)
+}
+
+// SeasonalPlayerStore is a PlayerStore whose league can be archived at the end of a season.
+type SeasonalPlayerStore interface {
+	PlayerStore
+	// CurrentSeason returns the season in play.
+	CurrentSeason(ctx context.Context) (Season, error)
+	// NewSeason archives the current season and starts a fresh league, returning the archived season.
+	NewSeason(ctx context.Context) (Season, error)
+	// Seasons returns every archived season, oldest first.
+	Seasons(ctx context.Context) ([]Season, error)
+}
+
+// leagueBetween counts the wins at or after since and before until, most wins first.
+func leagueBetween(wins []Win, since, until time.Time) League {
+	league := League{}
+
+	for _, w := range wins {
+		if w.Time.Before(since) || (!until.IsZero() && !w.Time.Before(until)) {
+			continue
+		}
+
+		if player := league.Find(w.Player); player != nil {
+			player.Wins++
+		} else {
+			league = append(league, Player{w.Player, 1})
+		}
+	}
+
+	sortLeague(league)
+
+	return league
+}
+
+// sortLeague orders a league by wins, most first, breaking ties by name.
+func sortLeague(league League) {
+	sort.Slice(league, func(i, j int) bool {
+		if league[i].Wins != league[j].Wins {
+			return league[i].Wins > league[j].Wins
+		}
+		return league[i].Name < league[j].Name
+	})
+}
+
+// reconcileWins changes the wins of the season that started at started by only as much as it
+// takes each player to go from the old league to league: wins a player gained are recorded at
+// now and wins they lost are their latest ones. Every other win keeps its time.
+func reconcileWins(wins []Win, old, league League, started, now time.Time) []Win {
+	gained := map[string]int{}
+
+	for _, p := range old {
+		gained[p.Name] -= p.Wins
+	}
+
+	for _, p := range league {
+		gained[p.Name] += p.Wins
+	}
+
+	kept := make([]bool, len(wins))
+
+	for i := len(wins) - 1; i >= 0; i-- {
+		w := wins[i]
+		kept[i] = true
+
+		if !w.Time.Before(started) && gained[w.Player] < 0 {
+			kept[i] = false
+			gained[w.Player]++
+		}
+	}
+
+	reconciled := []Win{}
+
+	for i, w := range wins {
+		if kept[i] {
+			reconciled = append(reconciled, w)
+		}
+	}
+
+	for _, p := range league {
+		for ; gained[p.Name] > 0; gained[p.Name]-- {
+			reconciled = append(reconciled, Win{p.Name, now})
+		}
+	}
+
+	return reconciled
+}
@@ -0,0 +1,157 @@
+package poker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileSystemStoreHistory(t *testing.T) {
+	october := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("counts wins within a window", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		now := october
+		store.now = func() time.Time { return now }
+
+		recordWin(t, store, "Cleo")
+		now = now.AddDate(0, 0, 7)
+		recordWin(t, store, "Chris")
+		recordWin(t, store, "Chris")
+		now = now.AddDate(0, 0, 7)
+		recordWin(t, store, "Cleo")
+
+		assertLeague(t, getLeagueBetween(t, store, october, october.AddDate(0, 0, 8)), []Player{{"Chris", 2}, {"Cleo", 1}})
+		assertLeague(t, getLeagueBetween(t, store, october.AddDate(0, 0, 1), time.Time{}), []Player{{"Chris", 2}, {"Cleo", 1}})
+		assertLeague(t, getLeagueBetween(t, store, time.Time{}, october.AddDate(0, 0, 7)), []Player{{"Cleo", 1}})
+		assertLeague(t, getLeagueBetween(t, store, time.Time{}, time.Time{}), []Player{{"Chris", 2}, {"Cleo", 2}})
+	})
+
+	t.Run("keeps the history across restarts", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+		store.now = func() time.Time { return october }
+
+		recordWin(t, store, "Cleo")
+
+		store, err = NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		assertLeague(t, getLeagueBetween(t, store, october, october.Add(time.Second)), []Player{{"Cleo", 1}})
+	})
+
+	t.Run("archives the season and starts a fresh league", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `{"version":2,"players":[{"Name":"Cleo","Wins":10}]}`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		now := october
+		store.now = func() time.Time { return now }
+
+		recordWin(t, store, "Chris")
+
+		now = now.Add(time.Hour)
+		archived, err := store.NewSeason(context.Background())
+		assertNoError(t, err)
+
+		assertSeason(t, archived, Season{Number: 1, Ended: october.Add(time.Hour), League: League{{"Cleo", 10}, {"Chris", 1}}})
+		assertLeague(t, getLeague(t, store), []Player{})
+
+		recordWin(t, store, "Chris")
+
+		store, err = NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		current, err := store.CurrentSeason(context.Background())
+		assertNoError(t, err)
+		assertSeason(t, current, Season{Number: 2, Started: october.Add(time.Hour)})
+
+		seasons, err := store.Seasons(context.Background())
+		assertNoError(t, err)
+
+		if len(seasons) != 1 {
+			t.Fatalf("got %d archived seasons want 1", len(seasons))
+		}
+		assertSeason(t, seasons[0], archived)
+
+		assertLeague(t, getLeague(t, store), []Player{{"Chris", 1}})
+		assertLeague(t, getLeagueBetween(t, store, time.Time{}, time.Time{}), []Player{{"Chris", 2}})
+	})
+
+	t.Run("replaces the wins of the current season with an imported league", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		now := october
+		store.now = func() time.Time { return now }
+
+		recordWin(t, store, "Cleo")
+
+		now = now.Add(time.Hour)
+		_, err = store.NewSeason(context.Background())
+		assertNoError(t, err)
+
+		recordWin(t, store, "Chris")
+
+		now = now.Add(time.Hour)
+		assertNoError(t, store.ReplaceLeague(context.Background(), League{{"Pepper", 2}, {"Chris", 1}}))
+
+		season := october.Add(time.Hour)
+		assertLeague(t, getLeagueBetween(t, store, season, time.Time{}), getLeague(t, store))
+		assertLeague(t, getLeagueBetween(t, store, time.Time{}, season), []Player{{"Cleo", 1}})
+	})
+
+	t.Run("keeps the times of wins an import leaves alone", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, "")
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		now := october
+		store.now = func() time.Time { return now }
+
+		for i := 0; i < 5; i++ {
+			recordWin(t, store, "Chris")
+		}
+		recordWin(t, store, "Pepper")
+
+		now = now.AddDate(0, 2, 0)
+		recordWin(t, store, "Pepper")
+		week := now.AddDate(0, 0, -7)
+
+		assertNoError(t, store.ReplaceLeague(context.Background(), League{{"Chris", 5}, {"Pepper", 1}, {"Cleo", 1}}))
+
+		assertLeague(t, getLeague(t, store), []Player{{"Chris", 5}, {"Cleo", 1}, {"Pepper", 1}})
+		assertLeague(t, getLeagueBetween(t, store, week, time.Time{}), []Player{{"Cleo", 1}})
+		assertLeague(t, getLeagueBetween(t, store, october, week), []Player{{"Chris", 5}, {"Pepper", 1}})
+	})
+}
+
+func getLeagueBetween(t testing.TB, store HistoryPlayerStore, since, until time.Time) League {
+	t.Helper()
+	league, err := store.GetLeagueBetween(context.Background(), since, until)
+	assertNoError(t, err)
+	return league
+}
+
+func assertSeason(t testing.TB, got, want Season) {
+	t.Helper()
+	if got.Number != want.Number || !got.Started.Equal(want.Started) || !got.Ended.Equal(want.Ended) || !reflect.DeepEqual(got.League, want.League) {
+		t.Errorf("got season %+v want %+v", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+// Package leaderboard draws a poker league as a table in the terminal.
+package leaderboard
+
+import (
+	poker "command-line-and-project-structure"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ANSI escape codes used when drawing in colour.
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	bold        = "\x1b[1m"
+	red         = "\x1b[31m"
+	green       = "\x1b[32m"
+	yellow      = "\x1b[33m"
+	cyan        = "\x1b[36m"
+	reset       = "\x1b[0m"
+)
+
+// DefaultWidth is how many columns the board fits in when no width is given.
+const DefaultWidth = 80
+
+const (
+	title  = "Poker league"
+	nobody = "Nobody has won a game yet"
+)
+
+// movement is how a player's rank changed since the board was last drawn.
+type movement int
+
+const (
+	unchanged movement = iota
+	up
+	down
+	entered
+)
+
+var arrows = map[movement]string{unchanged: " ", up: "▲", down: "▼", entered: "+"}
+var arrowColours = map[movement]string{up: green, down: red, entered: cyan}
+
+// Board draws a league, remembering each player's rank so the next drawing can
+// show who moved up or down since.
+type Board struct {
+	width  int
+	colour bool
+	redraw bool
+	ranks  map[string]int
+}
+
+// Option configures a Board.
+type Option func(*Board)
+
+// WithWidth fits the board in width columns, truncating names that don't fit.
+func WithWidth(width int) Option {
+	return func(b *Board) {
+		b.width = width
+	}
+}
+
+// WithColour draws the headings in bold and the rank movement arrows in colour.
+func WithColour(colour bool) Option {
+	return func(b *Board) {
+		b.colour = colour
+	}
+}
+
+// WithRedraw clears the terminal before each drawing, so the board refreshes in place.
+func WithRedraw(redraw bool) Option {
+	return func(b *Board) {
+		b.redraw = redraw
+	}
+}
+
+// New creates a Board that has not drawn anything yet.
+func New(options ...Option) *Board {
+	b := &Board{width: DefaultWidth}
+
+	for _, option := range options {
+		option(b)
+	}
+
+	return b
+}
+
+// Render draws league as it stood at the given time to w.
+func (b *Board) Render(w io.Writer, league poker.League, at time.Time) error {
+	var out strings.Builder
+
+	if b.redraw {
+		out.WriteString(clearScreen)
+	}
+
+	rankWidth := len(strconv.Itoa(len(league)))
+	winsWidth := len("WINS")
+	nameWidth := len("PLAYER")
+
+	for _, player := range league {
+		winsWidth = max(winsWidth, len(strconv.Itoa(player.Wins)))
+		nameWidth = max(nameWidth, utf8.RuneCountInString(player.Name))
+	}
+
+	updated := "updated " + at.Format("15:04:05")
+
+	// rank, arrow, name and wins with a space between each, wide enough for the
+	// heading if the terminal is.
+	fixedWidth := rankWidth + winsWidth + 5
+	nameWidth = max(nameWidth, len(title)+1+len(updated)-fixedWidth)
+	nameWidth = max(1, min(nameWidth, b.width-fixedWidth))
+	tableWidth := fixedWidth + nameWidth
+
+	heading := truncate(title, tableWidth)
+
+	if len(title)+1+len(updated) <= tableWidth {
+		heading = title + strings.Repeat(" ", tableWidth-len(title)-len(updated)) + updated
+	}
+
+	out.WriteString(b.paint(bold, heading) + "\n")
+
+	if len(league) == 0 {
+		out.WriteString(truncate(nobody, tableWidth) + "\n")
+	} else {
+		header := fmt.Sprintf("%*s   %-*s %*s", rankWidth, "#", nameWidth, "PLAYER", winsWidth, "WINS")
+		out.WriteString(b.paint(bold, header) + "\n")
+	}
+
+	ranks := make(map[string]int, len(league))
+
+	for i, player := range league {
+		rank := i + 1
+		ranks[player.Name] = rank
+		move := b.movement(player.Name, rank)
+
+		name := truncate(player.Name, nameWidth)
+		padding := strings.Repeat(" ", nameWidth-utf8.RuneCountInString(name))
+
+		if rank == 1 {
+			name = b.paint(yellow, name)
+		}
+
+		fmt.Fprintf(&out, "%*d %s %s%s %*d\n", rankWidth, rank, b.paint(arrowColours[move], arrows[move]), name, padding, winsWidth, player.Wins)
+	}
+
+	b.ranks = ranks
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// movement compares a player's rank with the last drawing. Nobody moves on the first.
+func (b *Board) movement(player string, rank int) movement {
+	if b.ranks == nil {
+		return unchanged
+	}
+
+	previous, ok := b.ranks[player]
+
+	switch {
+	case !ok:
+		return entered
+	case rank < previous:
+		return up
+	case rank > previous:
+		return down
+	}
+
+	return unchanged
+}
+
+// paint wraps s in colour if the board draws in colour.
+func (b *Board) paint(colour, s string) string {
+	if !b.colour || colour == "" {
+		return s
+	}
+
+	return colour + s + reset
+}
+
+// truncate shortens s to width runes, ending with an ellipsis if anything was cut.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+
+	if width <= 1 {
+		return string(runes[:width])
+	}
+
+	return string(runes[:width-1]) + "…"
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
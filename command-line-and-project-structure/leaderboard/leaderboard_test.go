@@ -0,0 +1,112 @@
+package leaderboard
+
+import (
+	"bytes"
+	poker "command-line-and-project-structure"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var at = time.Date(2026, time.October, 19, 20, 30, 0, 0, time.UTC)
+
+var league = poker.League{
+	{Name: "Chris", Wins: 33},
+	{Name: "Cleo", Wins: 10},
+	{Name: "Bartholomew Fitzwilliam", Wins: 7},
+	{Name: "Tiest", Wins: 5},
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name    string
+		options []Option
+		league  poker.League
+	}{
+		{"plain", nil, league},
+		{"colour", []Option{WithColour(true), WithRedraw(true)}, league},
+		{"narrow", []Option{WithWidth(24)}, league},
+		{"too narrow for the time", []Option{WithWidth(16)}, league},
+		{"short names", nil, poker.League{{Name: "Cleo", Wins: 2}, {Name: "Chris", Wins: 1}}},
+		{"empty", nil, poker.League{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got bytes.Buffer
+
+			if err := New(c.options...).Render(&got, c.league, at); err != nil {
+				t.Fatal(err)
+			}
+
+			assertGolden(t, got.Bytes())
+		})
+	}
+
+	t.Run("movement since the last refresh", func(t *testing.T) {
+		board := New(WithColour(true))
+		board.Render(&bytes.Buffer{}, league, at)
+
+		refreshed := poker.League{
+			{Name: "Chris", Wins: 33},
+			{Name: "Tiest", Wins: 12},
+			{Name: "Cleo", Wins: 10},
+			{Name: "Pepper", Wins: 8},
+			{Name: "Bartholomew Fitzwilliam", Wins: 7},
+		}
+
+		var got bytes.Buffer
+
+		if err := board.Render(&got, refreshed, at.Add(5*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+
+		assertGolden(t, got.Bytes())
+	})
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"Chris", 5, "Chris"},
+		{"Chris", 4, "Chr…"},
+		{"Chris", 1, "C"},
+		{"Zoë Åkesson", 4, "Zoë…"},
+	}
+
+	for _, c := range cases {
+		if got := truncate(c.s, c.width); got != c.want {
+			t.Errorf("truncate(%q, %d) got %q want %q", c.s, c.width, got, c.want)
+		}
+	}
+}
+
+// assertGolden compares got with the golden file named after the test, rewriting it with -update.
+func assertGolden(t testing.TB, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", filepath.Base(t.Name())+".golden")
+
+	if *update {
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatalf("could not update %s, %v", golden, err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+
+	if err != nil {
+		t.Fatalf("could not read %s, %v", golden, err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
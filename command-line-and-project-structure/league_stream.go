@@ -0,0 +1,102 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const eventStreamContentType = "text/event-stream"
+
+// leagueStreamPollInterval is how often league streams check for changes made outside the
+// server, such as a new season started from the CLI or wins recorded by another webserver.
+const leagueStreamPollInterval = 2 * time.Second
+
+// leagueChanges tells subscribers whenever the server changes the league.
+type leagueChanges struct {
+	mu          sync.Mutex
+	subscribers map[chan struct{}]bool
+}
+
+// subscribe returns a channel signalled after each change and a function to stop listening.
+func (l *leagueChanges) subscribe() (<-chan struct{}, func()) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.subscribers == nil {
+		l.subscribers = map[chan struct{}]bool{}
+	}
+
+	changed := make(chan struct{}, 1)
+	l.subscribers[changed] = true
+
+	return changed, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers, changed)
+	}
+}
+
+// notify signals every subscriber without waiting for slow ones; changes they
+// have not yet seen are coalesced into one.
+func (l *leagueChanges) notify() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for changed := range l.subscribers {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// leagueStreamHandler sends the league as a server-sent event when a client
+// connects and again every time it changes, until the client goes away.
+// Changes made through the server are sent straight away and any others
+// once the stream next polls the store.
+func (p *PlayerServer) leagueStreamHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	changed, unsubscribe := p.changes.subscribe()
+	defer unsubscribe()
+
+	poll := time.NewTicker(p.streamPoll)
+	defer poll.Stop()
+
+	w.Header().Set("content-type", eventStreamContentType)
+	w.Header().Set("cache-control", "no-cache")
+
+	var sent []byte
+
+	for {
+		league, err := p.store.GetLeague(r.Context())
+
+		if err != nil {
+			fmt.Fprintf(w, "event: error\ndata: could not get league\n\n")
+			flusher.Flush()
+			return
+		}
+
+		if data, _ := json.Marshal(league); sent == nil || !bytes.Equal(data, sent) {
+			fmt.Fprintf(w, "event: league\ndata: %s\n\n", data)
+			flusher.Flush()
+			sent = data
+		}
+
+		select {
+		case <-changed:
+		case <-poll.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package poker
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeagueStream(t *testing.T) {
+	t.Run("sends changes made outside the server", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t, `[]`)
+		defer cleanDatabase()
+
+		store, err := NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		players := NewPlayerServer(store)
+		players.streamPoll = 10 * time.Millisecond
+
+		server := httptest.NewServer(players)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		request, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/league/stream", nil)
+		response, err := http.DefaultClient.Do(request)
+		assertNoError(t, err)
+		defer response.Body.Close()
+
+		events := bufio.NewReader(response.Body)
+		assertNextLeagueEvent(t, events, `[]`)
+
+		win, err := server.Client().Post(server.URL+"/players/Cleo", "", nil)
+		assertNoError(t, err)
+		win.Body.Close()
+
+		assertNextLeagueEvent(t, events, `[{"Name":"Cleo","Wins":1}]`)
+
+		other, close, err := FileSystemFileStoreFromFile(database.Name())
+		assertNoError(t, err)
+		defer close()
+
+		_, err = other.NewSeason(ctx)
+		assertNoError(t, err)
+
+		assertNextLeagueEvent(t, events, `[]`)
+	})
+}
+
+// assertNextLeagueEvent reads the next server-sent event and checks it is the league want.
+func assertNextLeagueEvent(t testing.TB, events *bufio.Reader, want string) {
+	t.Helper()
+
+	var lines []string
+
+	for {
+		line, err := events.ReadString('\n')
+
+		if err != nil {
+			t.Fatalf("could not read league event, %v", err)
+		}
+
+		if line == "\n" {
+			break
+		}
+
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	if len(lines) != 2 || lines[0] != "event: league" || lines[1] != "data: "+want {
+		t.Errorf("got event %q want league %s", lines, want)
+	}
+}
@@ -0,0 +1,135 @@
+package poker
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Formats a league can be exported to and imported from.
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+)
+
+var csvHeader = []string{"Name", "Wins"}
+
+// ExportLeague writes league to w in format.
+func ExportLeague(w io.Writer, league League, format string) error {
+	switch format {
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(league)
+	case FormatCSV:
+		return writeLeagueCSV(w, league)
+	default:
+		return fmt.Errorf("unknown league format %q", format)
+	}
+}
+
+// ImportLeague reads a league from rdr in format, accepting any version of the database as JSON.
+func ImportLeague(rdr io.Reader, format string) (League, error) {
+	switch format {
+	case FormatJSON:
+		db, _, err := loadDatabase(rdr)
+		return db.Players, err
+	case FormatCSV:
+		return readLeagueCSV(rdr)
+	default:
+		return nil, fmt.Errorf("unknown league format %q", format)
+	}
+}
+
+func writeLeagueCSV(w io.Writer, league League) error {
+	out := csv.NewWriter(w)
+	out.Write(csvHeader)
+
+	for _, p := range league {
+		out.Write([]string{p.Name, strconv.Itoa(p.Wins)})
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func readLeagueCSV(rdr io.Reader) (League, error) {
+	records, err := csv.NewReader(rdr).ReadAll()
+
+	if err != nil {
+		return nil, fmt.Errorf("problem parsing league csv, %v", err)
+	}
+
+	league := League{}
+
+	for i, record := range records {
+		if i == 0 && record[0] == csvHeader[0] {
+			continue
+		}
+
+		if len(record) != len(csvHeader) {
+			return nil, fmt.Errorf("problem parsing league csv line %d, want %d fields got %d", i+1, len(csvHeader), len(record))
+		}
+
+		wins, err := strconv.Atoi(record[1])
+
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing league csv line %d, %v", i+1, err)
+		}
+
+		league = append(league, Player{record[0], wins})
+	}
+
+	return league, nil
+}
+
+// MergeLeagues returns current updated with every player in incoming, keeping players incoming does not mention.
+func MergeLeagues(current, incoming League) League {
+	merged := append(League{}, current...)
+
+	for _, p := range incoming {
+		if existing := merged.Find(p.Name); existing != nil {
+			existing.Wins = p.Wins
+		} else {
+			merged = append(merged, p)
+		}
+	}
+
+	return merged
+}
+
+// LeagueChange is the difference in a player's wins between two leagues.
+type LeagueChange struct {
+	Name   string
+	Before int
+	After  int
+}
+
+// DiffLeagues lists the players whose wins differ between before and after, by name.
+func DiffLeagues(before, after League) []LeagueChange {
+	var changes []LeagueChange
+
+	for _, p := range after {
+		was := 0
+		if old := before.Find(p.Name); old != nil {
+			was = old.Wins
+		}
+
+		if was != p.Wins {
+			changes = append(changes, LeagueChange{p.Name, was, p.Wins})
+		}
+	}
+
+	for _, p := range before {
+		if after.Find(p.Name) == nil {
+			changes = append(changes, LeagueChange{p.Name, p.Wins, 0})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
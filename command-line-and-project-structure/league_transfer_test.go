@@ -0,0 +1,73 @@
+package poker
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestExportImportLeague(t *testing.T) {
+	league := League{{"Chris", 33}, {"Cleo", 10}}
+
+	for _, format := range []string{FormatJSON, FormatCSV} {
+		t.Run(format+" round trips", func(t *testing.T) {
+			var buf bytes.Buffer
+
+			assertNoError(t, ExportLeague(&buf, league, format))
+
+			got, err := ImportLeague(&buf, format)
+			assertNoError(t, err)
+
+			assertLeague(t, got, league)
+		})
+	}
+
+	t.Run("csv has a header", func(t *testing.T) {
+		var buf bytes.Buffer
+		ExportLeague(&buf, league, FormatCSV)
+
+		assertResponseBody(t, buf.String(), "Name,Wins\nChris,33\nCleo,10\n")
+	})
+
+	t.Run("rejects bad csv wins", func(t *testing.T) {
+		_, err := ImportLeague(bytes.NewBufferString("Chris,lots\n"), FormatCSV)
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		err := ExportLeague(&bytes.Buffer{}, league, "xml")
+
+		if err == nil {
+			t.Error("expected an error but didn't get one")
+		}
+	})
+}
+
+func TestMergeLeagues(t *testing.T) {
+	current := League{{"Chris", 33}, {"Cleo", 10}}
+	incoming := League{{"Cleo", 12}, {"Pepper", 1}}
+
+	got := MergeLeagues(current, incoming)
+
+	assertLeague(t, got, []Player{{"Chris", 33}, {"Cleo", 12}, {"Pepper", 1}})
+	assertLeague(t, current, []Player{{"Chris", 33}, {"Cleo", 10}})
+}
+
+func TestDiffLeagues(t *testing.T) {
+	before := League{{"Chris", 33}, {"Cleo", 10}}
+	after := League{{"Cleo", 12}, {"Pepper", 1}}
+
+	got := DiffLeagues(before, after)
+	want := []LeagueChange{
+		{"Chris", 33, 0},
+		{"Cleo", 10, 12},
+		{"Pepper", 0, 1},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
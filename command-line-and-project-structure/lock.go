@@ -0,0 +1,9 @@
+package poker
+
+// lockType is the kind of advisory lock taken on a database file.
+type lockType int
+
+const (
+	lockShared lockType = iota
+	lockExclusive
+)
@@ -0,0 +1,384 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type openAPIDocument struct {
+	Paths      map[string]map[string]json.RawMessage
+	Components struct {
+		Schemas   map[string]openAPISchema
+		Responses map[string]openAPIResponse
+	}
+}
+
+type openAPIOperation struct {
+	Responses map[string]openAPIResponse
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema openAPISchema
+	}
+}
+
+type openAPISchema struct {
+	Ref        string `json:"$ref"`
+	Type       string
+	Items      *openAPISchema
+	Properties map[string]openAPISchema
+	Required   []string
+	Enum       []string
+}
+
+// openAPIFixture is a request that exercises one documented operation.
+type openAPIFixture struct {
+	name      string
+	operation string
+	store     PlayerStore
+	request   func() *http.Request
+}
+
+func TestOpenAPISpecification(t *testing.T) {
+	spec := loadOpenAPIDocument(t)
+
+	t.Run("it is served at /openapi.json", func(t *testing.T) {
+		server := NewPlayerServer(&StubPlayerStore{})
+		request, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+	})
+
+	t.Run("every route is documented and every documented path is routed", func(t *testing.T) {
+		server := newOpenAPITestServer(t, &StubPlayerStore{})
+
+		var routed []string
+		for _, r := range server.routes {
+			routed = append(routed, r.paths...)
+		}
+
+		var documented []string
+		for path := range spec.Paths {
+			documented = append(documented, path)
+		}
+
+		sort.Strings(routed)
+		sort.Strings(documented)
+
+		if strings.Join(routed, " ") != strings.Join(documented, " ") {
+			t.Errorf("routes %v do not match documented paths %v", routed, documented)
+		}
+	})
+
+	fixtures := openAPIFixtures()
+
+	t.Run("every documented operation is exercised", func(t *testing.T) {
+		exercised := map[string]bool{}
+		for _, f := range fixtures {
+			exercised[f.operation] = true
+		}
+
+		for path, item := range spec.Paths {
+			for method := range item {
+				if method == "parameters" {
+					continue
+				}
+
+				operation := strings.ToUpper(method) + " " + path
+				if !exercised[operation] {
+					t.Errorf("no fixture exercises %s", operation)
+				}
+			}
+		}
+	})
+
+	for _, f := range fixtures {
+		t.Run(f.name, func(t *testing.T) {
+			server := newOpenAPITestServer(t, f.store)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, f.request())
+
+			documented := spec.response(t, f.operation, response.Code)
+			spec.assertMatches(t, documented, response)
+		})
+	}
+}
+
+func openAPIFixtures() []openAPIFixture {
+	stub := func() PlayerStore {
+		return &StubPlayerStore{
+			scores: map[string]int{"Pepper": 20},
+			league: []Player{{"Pepper", 20}},
+			ledger: Ledger{Deposit("Pepper", 100), BuyIn("Pepper", "friday", 20)},
+		}
+	}
+	failing := func() PlayerStore { return failingPlayerStore{errors.New("disk full")} }
+	get := func(target string) func() *http.Request {
+		return func() *http.Request {
+			request, _ := http.NewRequest(http.MethodGet, target, nil)
+			return request
+		}
+	}
+
+	post := func(target, body string) func() *http.Request {
+		return func() *http.Request {
+			request, _ := http.NewRequest(http.MethodPost, target, strings.NewReader(body))
+			return request
+		}
+	}
+
+	command := func(text string, sign bool) func() *http.Request {
+		return func() *http.Request {
+			return newCommandRequest(text, sign)
+		}
+	}
+
+	return []openAPIFixture{
+		{"league", "GET /league", stub(), get("/league")},
+		{"unchanged league", "GET /league", stub(), func() *http.Request {
+			request := newLeagueRequest()
+			request.Header.Set("If-None-Match", "*")
+			return request
+		}},
+		{"league store failure", "GET /league", failing(), get("/league")},
+		{"league this month", "GET /league", stub(), get("/league?period=month")},
+		{"league between dates", "GET /league", stub(), get("/league?since=2026-10-01&until=2026-10-31")},
+		{"invalid league window", "GET /league", stub(), get("/league?period=fortnight")},
+		{"league window without history", "GET /league", failing(), get("/league?period=week")},
+		{"league stream", "GET /league/stream", stub(), func() *http.Request {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			request, _ := http.NewRequestWithContext(ctx, http.MethodGet, "/league/stream", nil)
+			return request
+		}},
+		{"score", "GET /players/{name}", stub(), func() *http.Request { return newGetScoreRequest("Pepper") }},
+		{"unknown player", "GET /players/{name}", stub(), func() *http.Request { return newGetScoreRequest("Apollo") }},
+		{"score store failure", "GET /players/{name}", failing(), func() *http.Request { return newGetScoreRequest("Pepper") }},
+		{"win", "POST /players/{name}", stub(), func() *http.Request { return newPostWinRequest("Pepper") }},
+		{"balance", "GET /players/{name}/balance", stub(), get("/players/Pepper/balance")},
+		{"balance without a bank", "GET /players/{name}/balance", failing(), get("/players/Pepper/balance")},
+		{"win store failure", "POST /players/{name}", failing(), func() *http.Request { return newPostWinRequest("Pepper") }},
+		{"audit", "GET /audit", stub(), get("/audit?player=Pepper")},
+		{"specification", "GET /openapi.json", stub(), get("/openapi.json")},
+		{"tournaments", "GET /tournaments", stub(), get("/tournaments")},
+		{"new tournament", "POST /tournaments", stub(), post("/tournaments", `{"name":"November","format":"swiss"}`)},
+		{"invalid tournament", "POST /tournaments", stub(), post("/tournaments", `{"name":"November","format":"ladder"}`)},
+		{"new tournament store failure", "POST /tournaments", failing(), post("/tournaments", `{"name":"November","format":"swiss"}`)},
+		{"tournament", "GET /tournaments/{id}", stub(), get("/tournaments/1")},
+		{"tournament page", "GET /tournaments/{id}", stub(), func() *http.Request {
+			request, _ := http.NewRequest(http.MethodGet, "/tournaments/1", nil)
+			request.Header.Set("Accept", "text/html")
+			return request
+		}},
+		{"unknown tournament", "GET /tournaments/{id}", stub(), get("/tournaments/9")},
+		{"match result", "POST /tournaments/{id}/matches/{match}", stub(), post("/tournaments/1/matches/1", `{"winner":"Pepper"}`)},
+		{"invalid match result", "POST /tournaments/{id}/matches/{match}", stub(), post("/tournaments/1/matches/1", `{"winner":"Apollo"}`)},
+		{"unknown match", "POST /tournaments/{id}/matches/{match}", stub(), post("/tournaments/1/matches/9", `{"winner":"Pepper"}`)},
+		{"match result store failure", "POST /tournaments/{id}/matches/{match}", failing(), post("/tournaments/1/matches/1", `{"winner":"Pepper"}`)},
+		{"win command", "POST /commands", stub(), command("Pepper wins", true)},
+		{"league command", "POST /commands", stub(), command("league", true)},
+		{"unknown command", "POST /commands", stub(), command("deal", true)},
+		{"unsigned command", "POST /commands", stub(), command("league", false)},
+		{"unreadable command", "POST /commands", stub(), func() *http.Request { return newSignedCommandRequest("text=%zz") }},
+		{"command store failure", "POST /commands", failing(), command("score Pepper", true)},
+	}
+}
+
+// newOpenAPITestServer creates a server with every optional route enabled.
+func newOpenAPITestServer(t testing.TB, store PlayerStore) *PlayerServer {
+	t.Helper()
+
+	file, clean := createTempFile(t, "")
+	t.Cleanup(clean)
+
+	auditLog := NewAuditLog(file)
+	auditLog.Append(AuditEntry{Action: AuditActionWin, Player: "Pepper", Source: AuditSourceHTTP})
+
+	tournamentsFile, cleanTournaments := createTempFile(t, "")
+	t.Cleanup(cleanTournaments)
+
+	tournaments, err := NewTournaments(tournamentsFile)
+	assertNoError(t, err)
+
+	_, err = tournaments.Create("October", SingleElimination, nil, []string{"Pepper", "Floyd"})
+	assertNoError(t, err)
+
+	return NewPlayerServer(store, WithAuditLog(auditLog), WithTournaments(tournaments), WithCommands(commandSecret))
+}
+
+func loadOpenAPIDocument(t testing.TB) openAPIDocument {
+	t.Helper()
+
+	var spec openAPIDocument
+
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("could not parse openapi.json, %v", err)
+	}
+
+	return spec
+}
+
+// response finds the documented response for status, failing if there isn't one.
+func (d openAPIDocument) response(t testing.TB, operation string, status int) openAPIResponse {
+	t.Helper()
+
+	method, path, _ := strings.Cut(operation, " ")
+
+	var op openAPIOperation
+	if err := json.Unmarshal(d.Paths[path][strings.ToLower(method)], &op); err != nil {
+		t.Fatalf("could not parse %s, %v", operation, err)
+	}
+
+	response, ok := op.Responses[strconv.Itoa(status)]
+
+	if !ok {
+		t.Fatalf("%s responded %d, which is not documented", operation, status)
+	}
+
+	if response.Ref != "" {
+		response = d.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+	}
+
+	return response
+}
+
+func (d openAPIDocument) assertMatches(t testing.TB, documented openAPIResponse, response *httptest.ResponseRecorder) {
+	t.Helper()
+
+	body := response.Body.String()
+
+	if len(documented.Content) == 0 {
+		if body != "" {
+			t.Errorf("expected no body but got %q", body)
+		}
+		return
+	}
+
+	contentType := response.Header().Get("content-type")
+
+	if contentType == "" {
+		// net/http sniffs the body when a handler doesn't set a type; the recorder doesn't.
+		contentType = http.DetectContentType(response.Body.Bytes())
+	}
+
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+	content, ok := documented.Content[contentType]
+
+	if !ok {
+		t.Fatalf("content type %q is not documented", contentType)
+	}
+
+	schema := d.resolve(content.Schema)
+
+	switch {
+	case contentType == jsonContentType:
+		var value interface{}
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+
+		if err := decoder.Decode(&value); err != nil {
+			t.Fatalf("could not parse response %q, %v", body, err)
+		}
+
+		d.assertSchema(t, schema, value, "body")
+	case schema.Type == "integer":
+		if _, err := strconv.Atoi(strings.TrimSpace(body)); err != nil {
+			t.Errorf("expected an integer body but got %q", body)
+		}
+	}
+}
+
+func (d openAPIDocument) assertSchema(t testing.TB, schema openAPISchema, value interface{}, at string) {
+	t.Helper()
+
+	schema = d.resolve(schema)
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			t.Errorf("%s: expected an object but got %v", at, value)
+			return
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				t.Errorf("%s: missing required property %q", at, name)
+			}
+		}
+
+		for name, property := range object {
+			propertySchema, ok := schema.Properties[name]
+			if !ok && len(schema.Properties) > 0 {
+				t.Errorf("%s: property %q is not documented", at, name)
+				continue
+			}
+			if ok {
+				d.assertSchema(t, propertySchema, property, at+"."+name)
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			t.Errorf("%s: expected an array but got %v", at, value)
+			return
+		}
+
+		for i, item := range items {
+			d.assertSchema(t, *schema.Items, item, fmt.Sprintf("%s[%d]", at, i))
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			t.Errorf("%s: expected a string but got %v", at, value)
+		}
+
+		if len(schema.Enum) > 0 && !contains(schema.Enum, s) {
+			t.Errorf("%s: %q is not one of %v", at, s, schema.Enum)
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if _, err := number.Int64(); !ok || err != nil {
+			t.Errorf("%s: expected an integer but got %v", at, value)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			t.Errorf("%s: expected a number but got %v", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			t.Errorf("%s: expected a boolean but got %v", at, value)
+		}
+	}
+}
+
+func (d openAPIDocument) resolve(schema openAPISchema) openAPISchema {
+	for schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
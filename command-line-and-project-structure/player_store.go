@@ -0,0 +1,51 @@
+package poker
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStoreUnavailable can be wrapped by a PlayerStore that cannot currently reach its storage,
+// so callers can tell a temporary outage from other failures.
+var ErrStoreUnavailable = errors.New("player store unavailable")
+
+// LegacyPlayerStore is the original PlayerStore interface, without contexts or errors.
+type LegacyPlayerStore interface {
+	GetPlayerScore(name string) int
+	RecordWin(name string)
+	GetLeague() League
+}
+
+// FromLegacyPlayerStore adapts a LegacyPlayerStore to PlayerStore.
+func FromLegacyPlayerStore(store LegacyPlayerStore) PlayerStore {
+	return legacyPlayerStore{store}
+}
+
+type legacyPlayerStore struct {
+	store LegacyPlayerStore
+}
+
+func (l legacyPlayerStore) GetPlayerScore(ctx context.Context, name string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return l.store.GetPlayerScore(name), nil
+}
+
+func (l legacyPlayerStore) RecordWin(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.store.RecordWin(name)
+	return nil
+}
+
+func (l legacyPlayerStore) GetLeague(ctx context.Context) (League, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return l.store.GetLeague(), nil
+}
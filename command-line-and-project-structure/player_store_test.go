@@ -0,0 +1,52 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type legacyStore struct {
+	scores map[string]int
+}
+
+func (l *legacyStore) GetPlayerScore(name string) int {
+	return l.scores[name]
+}
+
+func (l *legacyStore) RecordWin(name string) {
+	l.scores[name]++
+}
+
+func (l *legacyStore) GetLeague() League {
+	var league League
+	for name, wins := range l.scores {
+		league = append(league, Player{name, wins})
+	}
+	return league
+}
+
+func TestFromLegacyPlayerStore(t *testing.T) {
+	store := FromLegacyPlayerStore(&legacyStore{map[string]int{}})
+
+	t.Run("records and reports wins", func(t *testing.T) {
+		recordWin(t, store, "Chris")
+		recordWin(t, store, "Chris")
+
+		assertScoreEquals(t, getScore(t, store, "Chris"), 2)
+		assertLeague(t, getLeague(t, store), []Player{{"Chris", 2}})
+	})
+
+	t.Run("returns the context error once cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := store.RecordWin(ctx, "Chris")
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got %v want %v", err, context.Canceled)
+		}
+
+		assertScoreEquals(t, getScore(t, store, "Chris"), 2)
+	})
+}
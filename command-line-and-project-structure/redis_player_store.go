@@ -0,0 +1,374 @@
+package poker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultRedisLeagueKey is the sorted set RedisPlayerStore keeps the league in.
+const DefaultRedisLeagueKey = "poker:league"
+
+// RedisPlayerStore stores players in a sorted set on a server speaking the Redis protocol,
+// so several webservers can share one league.
+type RedisPlayerStore struct {
+	addr   string
+	key    string
+	window time.Duration
+	audit  storeAudit
+
+	mu   sync.Mutex
+	conn net.Conn
+	rdr  *bufio.Reader
+}
+
+// RedisPlayerStoreOption configures a RedisPlayerStore.
+type RedisPlayerStoreOption func(*RedisPlayerStore)
+
+// WithRedisAuditLog records every win in auditLog, on behalf of the Origin in the context of
+// the win, or origin for wins recorded without one.
+func WithRedisAuditLog(auditLog *AuditLog, origin Origin) RedisPlayerStoreOption {
+	return func(r *RedisPlayerStore) {
+		r.audit = storeAudit{auditLog, origin}
+	}
+}
+
+// WithRedisIdempotencyWindow sets how long idempotency keys are remembered.
+func WithRedisIdempotencyWindow(window time.Duration) RedisPlayerStoreOption {
+	return func(r *RedisPlayerStore) {
+		r.window = window
+	}
+}
+
+// NewRedisPlayerStore creates a RedisPlayerStore talking to the server at addr, keeping the league in key.
+func NewRedisPlayerStore(addr, key string, options ...RedisPlayerStoreOption) *RedisPlayerStore {
+	store := &RedisPlayerStore{addr: addr, key: key, window: DefaultIdempotencyWindow}
+
+	for _, option := range options {
+		option(store)
+	}
+
+	return store
+}
+
+// GetPlayerScore retrieves a player's score.
+func (r *RedisPlayerStore) GetPlayerScore(ctx context.Context, name string) (int, error) {
+	reply, err := r.do(ctx, "ZSCORE", r.key, name)
+
+	if err == errNilReply {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return parseRedisScore(reply)
+}
+
+// RecordWin will store a win for a player, incrementing wins if already known.
+func (r *RedisPlayerStore) RecordWin(ctx context.Context, name string) error {
+	if _, err := r.do(ctx, "ZINCRBY", r.key, "1", name); err != nil {
+		return err
+	}
+
+	r.audit.record(ctx, time.Now(), AuditActionWin, name, "")
+	return nil
+}
+
+// RecordWins records a win for each name in a MULTI transaction, so either all of them are kept or none.
+func (r *RedisPlayerStore) RecordWins(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return ctx.Err()
+	}
+
+	commands := [][]string{{"MULTI"}}
+
+	for _, name := range names {
+		commands = append(commands, []string{"ZINCRBY", r.key, "1", name})
+	}
+
+	replies, err := r.pipeline(ctx, append(commands, []string{"EXEC"})...)
+
+	if err != nil {
+		return err
+	}
+
+	if err := firstRESPError(replies); err != nil {
+		return fmt.Errorf("MULTI failed, %v", err)
+	}
+
+	results, ok := replies[len(replies)-1].([]interface{})
+
+	if !ok || len(results) != len(names) {
+		return fmt.Errorf("unexpected EXEC reply %v", replies[len(replies)-1])
+	}
+
+	if err := firstRESPError(results); err != nil {
+		return fmt.Errorf("ZINCRBY failed, %v", err)
+	}
+
+	for _, name := range names {
+		r.audit.record(ctx, time.Now(), AuditActionWin, name, "")
+	}
+
+	return nil
+}
+
+// RecordWinOnce stores a win for a player unless key was already used within the idempotency window,
+// failing with ErrIdempotencyKeyReused if it was used for another player. The key expires from its own
+// string beside the league, which is set in the same MULTI transaction as the win, so one is never
+// kept without the other.
+func (r *RedisPlayerStore) RecordWinOnce(ctx context.Context, name, key string) (bool, error) {
+	recorded, err := r.recordWinOnce(ctx, name, r.key+":idempotency:"+key)
+
+	if recorded {
+		r.audit.record(ctx, time.Now(), AuditActionWin, name, "")
+	}
+
+	return recorded, err
+}
+
+// recordWinOnce checks keyName under WATCH, so the transaction recording the win is aborted and tried
+// again if another client uses the key in between. The connection is dropped after an error reply so
+// nothing is left watched on it.
+func (r *RedisPlayerStore) recordWinOnce(ctx context.Context, name, keyName string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	window := strconv.FormatInt(r.window.Milliseconds(), 10)
+
+	for {
+		replies, err := r.send(ctx, []string{"WATCH", keyName}, []string{"GET", keyName})
+
+		if err != nil {
+			return false, err
+		}
+
+		if err := firstRESPError(replies); err != nil {
+			r.disconnect()
+			return false, fmt.Errorf("GET failed, %v", err)
+		}
+
+		if used, ok := replies[1].(string); ok {
+			if _, err := r.send(ctx, []string{"UNWATCH"}); err != nil {
+				return false, err
+			}
+
+			if used != name {
+				return false, ErrIdempotencyKeyReused
+			}
+
+			return false, nil
+		}
+
+		replies, err = r.send(ctx,
+			[]string{"MULTI"},
+			[]string{"SET", keyName, name, "PX", window},
+			[]string{"ZINCRBY", r.key, "1", name},
+			[]string{"EXEC"},
+		)
+
+		if err != nil {
+			return false, err
+		}
+
+		if err := firstRESPError(replies); err != nil {
+			r.disconnect()
+			return false, fmt.Errorf("MULTI failed, %v", err)
+		}
+
+		switch results := replies[len(replies)-1].(type) {
+		case nil:
+			continue
+		case []interface{}:
+			if err := firstRESPError(results); err != nil || len(results) != 2 {
+				return false, fmt.Errorf("unexpected EXEC reply %v", results)
+			}
+
+			return true, nil
+		default:
+			return false, fmt.Errorf("unexpected EXEC reply %v", results)
+		}
+	}
+}
+
+// GetLeague returns the scores of all the players, most wins first and then by name, as sorted
+// sets order members with the same score backwards.
+func (r *RedisPlayerStore) GetLeague(ctx context.Context) (League, error) {
+	reply, err := r.do(ctx, "ZREVRANGE", r.key, "0", "-1", "WITHSCORES")
+
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]interface{})
+
+	if !ok || len(values)%2 != 0 {
+		return nil, fmt.Errorf("unexpected ZREVRANGE reply %v", reply)
+	}
+
+	league := League{}
+
+	for i := 0; i < len(values); i += 2 {
+		name, _ := values[i].(string)
+		wins, err := parseRedisScore(values[i+1])
+
+		if err != nil {
+			return nil, err
+		}
+
+		league = append(league, Player{name, wins})
+	}
+
+	sortLeague(league)
+	return league, nil
+}
+
+// Close closes the connection to the server.
+func (r *RedisPlayerStore) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.disconnect()
+}
+
+// do sends a command and reads its reply, connecting first if needed.
+func (r *RedisPlayerStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	replies, err := r.pipeline(ctx, args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch reply := replies[0].(type) {
+	case respError:
+		return nil, fmt.Errorf("%s failed, %v", args[0], reply)
+	case nil:
+		return nil, errNilReply
+	default:
+		return reply, nil
+	}
+}
+
+// pipeline sends commands together and reads a reply to each, connecting first if needed.
+// Error and nil replies are returned among the others as a respError or nil. The connection
+// is dropped after any network or protocol error so the next command reconnects.
+func (r *RedisPlayerStore) pipeline(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.send(ctx, commands...)
+}
+
+// send is pipeline for callers already holding the lock, so they can keep the connection
+// between round trips, as WATCH needs.
+func (r *RedisPlayerStore) send(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	if err := r.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	deadline, _ := ctx.Deadline()
+	r.conn.SetDeadline(deadline)
+
+	name := commands[0][0]
+	var buf bytes.Buffer
+
+	for _, args := range commands {
+		writeRESPCommand(&buf, args...)
+	}
+
+	if _, err := r.conn.Write(buf.Bytes()); err != nil {
+		r.disconnect()
+		return nil, fmt.Errorf("problem sending %s, %w", name, ErrStoreUnavailable)
+	}
+
+	replies := make([]interface{}, len(commands))
+
+	for i := range replies {
+		reply, err := readRESP(r.rdr)
+
+		switch err := err.(type) {
+		case nil:
+			replies[i] = reply
+		case respError:
+			replies[i] = err
+		default:
+			if err == errNilReply {
+				continue
+			}
+
+			r.disconnect()
+
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			return nil, fmt.Errorf("problem reading %s reply, %v, %w", name, err, ErrStoreUnavailable)
+		}
+	}
+
+	return replies, nil
+}
+
+func (r *RedisPlayerStore) connect(ctx context.Context) error {
+	if r.conn != nil {
+		return nil
+	}
+
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr)
+
+	if err != nil {
+		return fmt.Errorf("problem connecting to %s, %v, %w", r.addr, err, ErrStoreUnavailable)
+	}
+
+	r.conn = conn
+	r.rdr = bufio.NewReader(conn)
+
+	return nil
+}
+
+func (r *RedisPlayerStore) disconnect() error {
+	if r.conn == nil {
+		return nil
+	}
+
+	err := r.conn.Close()
+	r.conn = nil
+	r.rdr = nil
+
+	return err
+}
+
+// firstRESPError returns the first error among replies, or nil if there are none.
+func firstRESPError(replies []interface{}) error {
+	for _, reply := range replies {
+		if err, ok := reply.(respError); ok {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseRedisScore(reply interface{}) (int, error) {
+	score, ok := reply.(string)
+
+	if !ok {
+		return 0, fmt.Errorf("unexpected score reply %v", reply)
+	}
+
+	wins, err := strconv.ParseFloat(score, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("problem parsing score %q, %v", score, err)
+	}
+
+	return int(wins), nil
+}
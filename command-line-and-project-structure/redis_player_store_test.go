@@ -0,0 +1,173 @@
+package poker
+
+import (
+	"command-line-and-project-structure/redistest"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRedisPlayerStore(t *testing.T) {
+	server, err := redistest.NewServer()
+	assertNoError(t, err)
+	defer server.Close()
+
+	store := NewRedisPlayerStore(server.Addr(), DefaultRedisLeagueKey)
+	defer store.Close()
+
+	recordWin(t, store, "Cleo")
+	recordWin(t, store, "Chris")
+	recordWin(t, store, "Chris")
+
+	t.Run("get player score", func(t *testing.T) {
+		assertScoreEquals(t, getScore(t, store, "Chris"), 2)
+	})
+
+	t.Run("unknown players have no wins", func(t *testing.T) {
+		assertScoreEquals(t, getScore(t, store, "Apollo"), 0)
+	})
+
+	t.Run("league sorted", func(t *testing.T) {
+		assertLeague(t, getLeague(t, store), []Player{{"Chris", 2}, {"Cleo", 1}})
+	})
+
+	t.Run("stores share the league", func(t *testing.T) {
+		other := NewRedisPlayerStore(server.Addr(), DefaultRedisLeagueKey)
+		defer other.Close()
+
+		recordWin(t, other, "Cleo")
+
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 2)
+	})
+
+	t.Run("records a batch of wins in one transaction", func(t *testing.T) {
+		batch := NewRedisPlayerStore(server.Addr(), "poker:batch")
+		defer batch.Close()
+
+		assertNoError(t, batch.RecordWins(context.Background(), []string{"Cleo", "Chris", "Cleo"}))
+		assertLeague(t, getLeague(t, batch), []Player{{"Cleo", 2}, {"Chris", 1}})
+
+		recordWin(t, batch, "Chris")
+		assertScoreEquals(t, getScore(t, batch, "Chris"), 2)
+	})
+
+	t.Run("audits wins", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		auditLog := NewAuditLog(file)
+		audited := NewRedisPlayerStore(server.Addr(), "poker:audited", WithRedisAuditLog(auditLog, cliOrigin))
+		defer audited.Close()
+
+		recordWin(t, audited, "Cleo")
+
+		entries, err := auditLog.Entries("")
+		assertNoError(t, err)
+		assertAuditPlayers(t, entries, "Cleo")
+
+		if entries[0].Actor != cliOrigin.Actor || entries[0].Action != AuditActionWin {
+			t.Errorf("unexpected audit entry %+v", entries[0])
+		}
+	})
+
+	t.Run("leagues are kept apart by key", func(t *testing.T) {
+		other := NewRedisPlayerStore(server.Addr(), "poker:other")
+		defer other.Close()
+
+		assertLeague(t, getLeague(t, other), []Player{})
+	})
+
+	t.Run("records a win once per idempotency key across stores", func(t *testing.T) {
+		stores := make([]*RedisPlayerStore, 4)
+
+		for i := range stores {
+			stores[i] = NewRedisPlayerStore(server.Addr(), "poker:once")
+			defer stores[i].Close()
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		recorded := 0
+
+		for _, store := range stores {
+			wg.Add(1)
+			go func(store *RedisPlayerStore) {
+				defer wg.Done()
+				ok, err := store.RecordWinOnce(context.Background(), "Cleo", "abc")
+				if err != nil {
+					t.Error(err)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if ok {
+					recorded++
+				}
+			}(store)
+		}
+
+		wg.Wait()
+
+		if recorded != 1 {
+			t.Errorf("got %d wins recorded with one key want 1", recorded)
+		}
+
+		assertScoreEquals(t, getScore(t, stores[0], "Cleo"), 1)
+
+		if _, err := stores[0].RecordWinOnce(context.Background(), "Chris", "abc"); !errors.Is(err, ErrIdempotencyKeyReused) {
+			t.Errorf("got error %v want %v", err, ErrIdempotencyKeyReused)
+		}
+	})
+
+	t.Run("records retried requests to the server once", func(t *testing.T) {
+		retried := NewRedisPlayerStore(server.Addr(), "poker:retried")
+		defer retried.Close()
+
+		players := NewPlayerServer(retried)
+
+		for i := 0; i < 3; i++ {
+			request := newPostWinRequest("Pepper")
+			request.Header.Set("Idempotency-Key", "retry-me")
+			response := httptest.NewRecorder()
+
+			players.ServeHTTP(response, request)
+
+			assertStatus(t, response.Code, http.StatusAccepted)
+		}
+
+		assertScoreEquals(t, getScore(t, retried, "Pepper"), 1)
+	})
+
+	t.Run("forgets idempotency keys after the window", func(t *testing.T) {
+		windowed := NewRedisPlayerStore(server.Addr(), "poker:windowed", WithRedisIdempotencyWindow(20*time.Millisecond))
+		defer windowed.Close()
+
+		assertRecorded(t, recordWinOnce(t, windowed, "Cleo", "abc"), true)
+		assertRecorded(t, recordWinOnce(t, windowed, "Cleo", "abc"), false)
+
+		time.Sleep(50 * time.Millisecond)
+
+		assertRecorded(t, recordWinOnce(t, windowed, "Cleo", "abc"), true)
+		assertScoreEquals(t, getScore(t, windowed, "Cleo"), 2)
+	})
+}
+
+func TestRedisPlayerStoreUnavailable(t *testing.T) {
+	server, err := redistest.NewServer()
+	assertNoError(t, err)
+
+	store := NewRedisPlayerStore(server.Addr(), DefaultRedisLeagueKey)
+	defer store.Close()
+
+	recordWin(t, store, "Cleo")
+	server.Close()
+
+	err = store.RecordWin(context.Background(), "Cleo")
+
+	if !errors.Is(err, ErrStoreUnavailable) {
+		t.Errorf("got %v want %v", err, ErrStoreUnavailable)
+	}
+}
@@ -0,0 +1,384 @@
+// Package redistest is a fake server speaking enough of the Redis protocol for poker.RedisPlayerStore,
+// so its tests run without a real Redis.
+package redistest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxArgLength bounds the length of a command or any of its arguments, so a malformed request
+// cannot make the server allocate without limit.
+const maxArgLength = 1 << 20
+
+// Server is a tiny in-process server keeping sorted sets and strings in memory.
+type Server struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	sets   map[string]map[string]float64
+	values map[string]stringValue
+	// versions counts the writes to each key, so EXEC can tell whether a WATCHed key changed.
+	versions map[string]int
+	conns    map[net.Conn]bool
+}
+
+// stringValue is a string and when it expires, if it does.
+type stringValue struct {
+	value   string
+	expires time.Time
+}
+
+// NewServer starts a Server listening on a free local port.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		return nil, fmt.Errorf("problem starting fake redis server, %v", err)
+	}
+
+	f := &Server{
+		listener: listener,
+		sets:     map[string]map[string]float64{},
+		values:   map[string]stringValue{},
+		versions: map[string]int{},
+		conns:    map[net.Conn]bool{},
+	}
+
+	f.wg.Add(1)
+	go f.serve()
+
+	return f, nil
+}
+
+// Addr is the address the server is listening on.
+func (f *Server) Addr() string {
+	return f.listener.Addr().String()
+}
+
+// Close stops the server, dropping every client connection.
+func (f *Server) Close() error {
+	err := f.listener.Close()
+
+	f.mu.Lock()
+	for conn := range f.conns {
+		conn.Close()
+	}
+	f.mu.Unlock()
+
+	f.wg.Wait()
+	return err
+}
+
+func (f *Server) serve() {
+	defer f.wg.Done()
+
+	for {
+		conn, err := f.listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		f.mu.Lock()
+		f.conns[conn] = true
+		f.mu.Unlock()
+
+		f.wg.Add(1)
+		go f.handle(conn)
+	}
+}
+
+func (f *Server) handle(conn net.Conn) {
+	defer f.wg.Done()
+	defer func() {
+		f.mu.Lock()
+		delete(f.conns, conn)
+		f.mu.Unlock()
+		conn.Close()
+	}()
+
+	rdr := bufio.NewReader(conn)
+
+	// queued holds the commands of a MULTI transaction until EXEC, nil outside of one.
+	var queued [][]string
+	// watched holds the version of each WATCHed key when it was watched.
+	var watched map[string]int
+
+	for {
+		command, err := readCommand(rdr)
+
+		if err != nil {
+			io.WriteString(conn, fmt.Sprintf("-ERR Protocol error: %v\r\n", err))
+			return
+		}
+
+		switch name := strings.ToUpper(command[0]); {
+		case name == "WATCH" && queued == nil && len(command) > 1:
+			watched = f.watch(watched, command[1:])
+			io.WriteString(conn, "+OK\r\n")
+		case name == "UNWATCH" && queued == nil:
+			watched = nil
+			io.WriteString(conn, "+OK\r\n")
+		case name == "MULTI" && queued == nil:
+			queued = [][]string{}
+			io.WriteString(conn, "+OK\r\n")
+		case name == "EXEC" && queued != nil:
+			io.WriteString(conn, f.executeAll(queued, watched))
+			queued, watched = nil, nil
+		case name == "DISCARD" && queued != nil:
+			queued, watched = nil, nil
+			io.WriteString(conn, "+OK\r\n")
+		case queued != nil:
+			queued = append(queued, command)
+			io.WriteString(conn, "+QUEUED\r\n")
+		default:
+			io.WriteString(conn, f.execute(command))
+		}
+	}
+}
+
+// watch adds the current versions of keys to watched.
+func (f *Server) watch(watched map[string]int, keys []string) map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if watched == nil {
+		watched = map[string]int{}
+	}
+
+	for _, key := range keys {
+		watched[key] = f.versions[key]
+	}
+
+	return watched
+}
+
+// executeAll runs the commands of a transaction with nothing in between, replying with an array of their
+// replies, or aborts it with a nil reply if any watched key was written to since it was watched.
+func (f *Server) executeAll(commands [][]string, watched map[string]int) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for key, version := range watched {
+		if f.versions[key] != version {
+			return "*-1\r\n"
+		}
+	}
+
+	replies := make([]string, len(commands))
+
+	for i, command := range commands {
+		replies[i] = f.run(command)
+	}
+
+	return fmt.Sprintf("*%d\r\n%s", len(replies), strings.Join(replies, ""))
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	count, err := readLength(r, '*')
+
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+
+	command := make([]string, 0, count)
+
+	for i := 0; i < count; i++ {
+		size, err := readLength(r, '$')
+
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, size+2)
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		command = append(command, string(data[:size]))
+	}
+
+	return command, nil
+}
+
+// readLength reads a line holding the length of an array or bulk string, marked by kind.
+func readLength(r *bufio.Reader, kind byte) (int, error) {
+	line, err := r.ReadString('\n')
+
+	if err != nil {
+		return 0, err
+	}
+
+	line = strings.TrimSuffix(line, "\r\n")
+
+	if line == "" || line[0] != kind {
+		return 0, fmt.Errorf("expected '%c', got %q", kind, line)
+	}
+
+	size, err := strconv.Atoi(line[1:])
+
+	if err != nil || size < 0 || size > maxArgLength {
+		return 0, fmt.Errorf("invalid length %q", line[1:])
+	}
+
+	return size, nil
+}
+
+func (f *Server) execute(command []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.run(command)
+}
+
+func (f *Server) run(command []string) string {
+	switch name, args := strings.ToUpper(command[0]), command[1:]; {
+	case name == "PING":
+		return "+PONG\r\n"
+	case name == "ZINCRBY" && len(args) == 3:
+		by, err := strconv.ParseFloat(args[1], 64)
+
+		if err != nil {
+			return "-ERR value is not a valid float\r\n"
+		}
+
+		set := f.set(args[0])
+		set[args[2]] += by
+		f.versions[args[0]]++
+
+		return respBulk(formatRedisScore(set[args[2]]))
+	case name == "GET" && len(args) == 1:
+		value, ok := f.values[args[0]]
+
+		if !ok || (!value.expires.IsZero() && !time.Now().Before(value.expires)) {
+			return "$-1\r\n"
+		}
+
+		return respBulk(value.value)
+	case name == "SET" && (len(args) == 2 || len(args) == 4):
+		value := stringValue{value: args[1]}
+
+		if len(args) == 4 {
+			ms, err := strconv.Atoi(args[3])
+
+			if strings.ToUpper(args[2]) != "PX" || err != nil || ms <= 0 {
+				return "-ERR syntax error\r\n"
+			}
+
+			value.expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+
+		f.values[args[0]] = value
+		f.versions[args[0]]++
+
+		return "+OK\r\n"
+	case name == "ZSCORE" && len(args) == 2:
+		score, ok := f.set(args[0])[args[1]]
+
+		if !ok {
+			return "$-1\r\n"
+		}
+
+		return respBulk(formatRedisScore(score))
+	case name == "ZREVRANGE" && (len(args) == 3 || len(args) == 4):
+		return f.zrevrange(args)
+	case name == "DEL":
+		deleted := 0
+
+		for _, key := range args {
+			_, isSet := f.sets[key]
+			_, isString := f.values[key]
+
+			if isSet || isString {
+				delete(f.sets, key)
+				delete(f.values, key)
+				f.versions[key]++
+				deleted++
+			}
+		}
+
+		return fmt.Sprintf(":%d\r\n", deleted)
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", command[0])
+	}
+}
+
+func (f *Server) zrevrange(args []string) string {
+	start, err1 := strconv.Atoi(args[1])
+	stop, err2 := strconv.Atoi(args[2])
+
+	if err1 != nil || err2 != nil {
+		return "-ERR value is not an integer or out of range\r\n"
+	}
+
+	withScores := len(args) == 4 && strings.ToUpper(args[3]) == "WITHSCORES"
+	set := f.set(args[0])
+
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if set[members[i]] != set[members[j]] {
+			return set[members[i]] > set[members[j]]
+		}
+		return members[i] > members[j]
+	})
+
+	if start < 0 {
+		start += len(members)
+	}
+	if stop < 0 {
+		stop += len(members)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= len(members) {
+		stop = len(members) - 1
+	}
+
+	var reply []string
+
+	for i := start; i <= stop; i++ {
+		reply = append(reply, respBulk(members[i]))
+
+		if withScores {
+			reply = append(reply, respBulk(formatRedisScore(set[members[i]])))
+		}
+	}
+
+	return fmt.Sprintf("*%d\r\n%s", len(reply), strings.Join(reply, ""))
+}
+
+func (f *Server) set(key string) map[string]float64 {
+	if f.sets[key] == nil {
+		f.sets[key] = map[string]float64{}
+	}
+
+	return f.sets[key]
+}
+
+func respBulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func formatRedisScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
@@ -0,0 +1,143 @@
+package poker
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// respError is an error reply sent by a RESP server.
+type respError string
+
+func (e respError) Error() string {
+	return string(e)
+}
+
+// errNilReply is returned when a RESP server answers with a nil bulk string or array.
+var errNilReply = errors.New("nil reply")
+
+// Bounds on the replies readRESP accepts, far beyond anything a league needs, so a misbehaving
+// server cannot make the store allocate without limit. Lines other than bulk strings must also
+// fit in the reader's buffer.
+const (
+	maxRESPBulkLength  = 1 << 20
+	maxRESPArrayLength = 1 << 20
+)
+
+// writeRESPCommand writes args as a RESP array of bulk strings, the form every command is sent in.
+func writeRESPCommand(w io.Writer, args ...string) error {
+	buf := []byte(fmt.Sprintf("*%d\r\n", len(args)))
+
+	for _, arg := range args {
+		buf = append(buf, fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)...)
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// readRESP reads a single reply. Simple and bulk strings are returned as string,
+// integers as int64, arrays as []interface{} and error replies as a respError.
+// Every element of an array is read, even after an error or nil element, which is
+// kept in the array as a respError or nil, so the reply is never left half read.
+// Any other error means the connection can no longer be trusted and must be closed.
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := readRESPLine(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if line == "" {
+		return nil, fmt.Errorf("empty RESP line")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, respError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := readRESPLength(line, maxRESPBulkLength)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data := make([]byte, size+2)
+
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		return string(data[:size]), nil
+	case '*':
+		count, err := readRESPLength(line, maxRESPArrayLength)
+
+		if err != nil {
+			return nil, err
+		}
+
+		values := []interface{}{}
+
+		for i := 0; i < count; i++ {
+			value, err := readRESP(r)
+
+			switch err := err.(type) {
+			case nil:
+				values = append(values, value)
+			case respError:
+				values = append(values, err)
+			default:
+				if err != errNilReply {
+					return nil, err
+				}
+
+				values = append(values, nil)
+			}
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown RESP type %q", line[0])
+	}
+}
+
+// readRESPLength parses the length of the bulk string or array on line, returning errNilReply
+// for the nil length -1 and an error for anything else that isn't a length up to max.
+func readRESPLength(line string, max int) (int, error) {
+	size, err := strconv.Atoi(line[1:])
+
+	switch {
+	case err != nil || size < -1 || size > max:
+		return 0, fmt.Errorf("malformed RESP length %q", line)
+	case size == -1:
+		return 0, errNilReply
+	}
+
+	return size, nil
+}
+
+func readRESPLine(r *bufio.Reader) (string, error) {
+	data, err := r.ReadSlice('\n')
+
+	if err == bufio.ErrBufferFull {
+		return "", fmt.Errorf("RESP line longer than %d bytes", r.Size())
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	line := string(data)
+
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("malformed RESP line %q", line)
+	}
+
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,82 @@
+package poker
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadRESP(t *testing.T) {
+	t.Run("reads each kind of reply", func(t *testing.T) {
+		cases := []struct {
+			reply string
+			want  interface{}
+		}{
+			{"+OK\r\n", "OK"},
+			{":42\r\n", int64(42)},
+			{"$5\r\nCleo!\r\n", "Cleo!"},
+			{"*2\r\n$4\r\nCleo\r\n:1\r\n", []interface{}{"Cleo", int64(1)}},
+			{"*0\r\n", []interface{}{}},
+		}
+
+		for _, c := range cases {
+			got, err := readRESP(bufio.NewReader(strings.NewReader(c.reply)))
+			assertNoError(t, err)
+
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("reading %q got %#v want %#v", c.reply, got, c.want)
+			}
+		}
+	})
+
+	t.Run("reports nil replies", func(t *testing.T) {
+		for _, reply := range []string{"$-1\r\n", "*-1\r\n"} {
+			if _, err := readRESP(bufio.NewReader(strings.NewReader(reply))); err != errNilReply {
+				t.Errorf("reading %q got error %v want %v", reply, err, errNilReply)
+			}
+		}
+	})
+
+	t.Run("refuses malformed and oversized lengths", func(t *testing.T) {
+		replies := []string{
+			"$abc\r\n",
+			"$-2\r\n",
+			"$2000000\r\n",
+			"*-5\r\n",
+			"*9999999999\r\n",
+			"+" + strings.Repeat("x", 5000) + "\r\n",
+		}
+
+		for _, reply := range replies {
+			_, err := readRESP(bufio.NewReader(strings.NewReader(reply)))
+
+			if err == nil || err == errNilReply {
+				t.Errorf("reading %.20q got error %v want a protocol error", reply, err)
+			}
+
+			if _, ok := err.(respError); ok {
+				t.Errorf("reading %.20q got a server error %v want a protocol error", reply, err)
+			}
+		}
+	})
+
+	t.Run("reads a whole array holding error and nil elements", func(t *testing.T) {
+		rdr := bufio.NewReader(strings.NewReader("*3\r\n-ERR no\r\n$-1\r\n:1\r\n+PONG\r\n"))
+
+		got, err := readRESP(rdr)
+		assertNoError(t, err)
+
+		want := []interface{}{respError("ERR no"), nil, int64(1)}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %#v want %#v", got, want)
+		}
+
+		next, err := readRESP(rdr)
+		assertNoError(t, err)
+
+		if next != "PONG" {
+			t.Errorf("got next reply %v want PONG", next)
+		}
+	})
+}
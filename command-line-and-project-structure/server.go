@@ -1,19 +1,37 @@
 package poker
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 // PlayerStore stores score information about players.
 type PlayerStore interface {
-	GetPlayerScore(name string) int
-	RecordWin(name string)
-	GetLeague() League
+	GetPlayerScore(ctx context.Context, name string) (int, error)
+	RecordWin(ctx context.Context, name string) error
+	GetLeague(ctx context.Context) (League, error)
 }
 
+// IdempotentPlayerStore is a PlayerStore that can recognise retried wins.
+type IdempotentPlayerStore interface {
+	PlayerStore
+	// RecordWinOnce records a win unless key has already been used, reporting whether it did.
+	RecordWinOnce(ctx context.Context, name, key string) (bool, error)
+}
+
+// ErrIdempotencyKeyReused is returned by RecordWinOnce when key was used for another player's win.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for another player")
+
 // Player stores a name with a number of wins.
 type Player struct {
 	Name string
@@ -22,45 +40,284 @@ type Player struct {
 
 // PlayerServer is a HTTP interface for player information.
 type PlayerServer struct {
-	store PlayerStore
+	store       PlayerStore
+	version     leagueVersion
+	changes     leagueChanges
+	streamPoll  time.Duration
+	now         func() time.Time
+	auditLog    *AuditLog
+	tournaments *Tournaments
+	webhooks    *Webhooks
+	commands    []byte
+	replays     seenCommands
+	hstsMaxAge  time.Duration
+	leader      leaderTracker
+	routes      []route
 	http.Handler
 }
 
+// route is a handler and the pattern it is registered under, with the paths
+// it serves as documented in the OpenAPI specification.
+type route struct {
+	pattern string
+	paths   []string
+	handler http.HandlerFunc
+}
+
+// PlayerServerOption configures a PlayerServer.
+type PlayerServerOption func(*PlayerServer)
+
+// WithAuditLog serves auditLog at /audit. Changes are audited by the store, which is told who
+// made each request through its context.
+func WithAuditLog(auditLog *AuditLog) PlayerServerOption {
+	return func(p *PlayerServer) {
+		p.auditLog = auditLog
+	}
+}
+
+// WithTournaments serves tournaments at /tournaments, recording a win in the league for every match won.
+func WithTournaments(tournaments *Tournaments) PlayerServerOption {
+	return func(p *PlayerServer) {
+		p.tournaments = tournaments
+	}
+}
+
+// WithWebhooks sends an event to webhooks for every win recorded through the server and whenever the lead changes.
+func WithWebhooks(webhooks *Webhooks) PlayerServerOption {
+	return func(p *PlayerServer) {
+		p.webhooks = webhooks
+	}
+}
+
+// WithCommands serves chat slash commands at /commands, accepting only requests signed with signingSecret.
+func WithCommands(signingSecret []byte) PlayerServerOption {
+	return func(p *PlayerServer) {
+		p.commands = signingSecret
+	}
+}
+
 const jsonContentType = "application/json"
 
 // NewPlayerServer creates a PlayerServer with routing configured.
-func NewPlayerServer(store PlayerStore) *PlayerServer {
+func NewPlayerServer(store PlayerStore, options ...PlayerServerOption) *PlayerServer {
 	p := new(PlayerServer)
 
 	p.store = store
+	p.now = time.Now
+	p.streamPoll = leagueStreamPollInterval
+
+	for _, option := range options {
+		option(p)
+	}
+
+	if p.webhooks != nil {
+		if league, err := store.GetLeague(context.Background()); err == nil {
+			p.leader.observe(league)
+		}
+	}
+
+	p.routes = []route{
+		{"/league", []string{"/league"}, p.leagueHandler},
+		{"/league/stream", []string{"/league/stream"}, p.leagueStreamHandler},
+		{"/players/", []string{"/players/{name}", "/players/{name}/balance"}, p.playersHandler},
+		{"/openapi.json", []string{"/openapi.json"}, openAPIHandler},
+	}
+
+	if p.auditLog != nil {
+		p.routes = append(p.routes, route{"/audit", []string{"/audit"}, p.auditHandler})
+	}
+
+	if p.tournaments != nil {
+		p.routes = append(p.routes,
+			route{"/tournaments", []string{"/tournaments"}, p.tournamentsHandler},
+			route{"/tournaments/", []string{"/tournaments/{id}", "/tournaments/{id}/matches/{match}"}, p.tournamentHandler},
+		)
+	}
+
+	if p.commands != nil {
+		p.routes = append(p.routes, route{"/commands", []string{"/commands"}, p.commandsHandler})
+	}
 
 	router := http.NewServeMux()
-	router.Handle("/league", http.HandlerFunc(p.leagueHandler))
-	router.Handle("/players/", http.HandlerFunc(p.playersHandler))
 
-	p.Handler = router
+	for _, r := range p.routes {
+		router.Handle(r.pattern, r.handler)
+	}
+
+	p.Handler = withOrigin(router)
+
+	if p.hstsMaxAge > 0 {
+		p.Handler = hsts(p.Handler, p.hstsMaxAge)
+	}
 
 	return p
 }
 
+// leagueHandler serves the league of the current season, or the league over a window of
+// time given by since and until or a period of week or month.
 func (p *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
+	since, until, err := leagueWindow(r.URL.Query(), p.now())
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	windowed := !since.IsZero() || !until.IsZero()
+	history, keepsHistory := p.store.(HistoryPlayerStore)
+
+	var league League
+
+	switch {
+	case !windowed:
+		league, err = p.store.GetLeague(r.Context())
+	case keepsHistory:
+		league, err = history.GetLeagueBetween(r.Context(), since, until)
+	default:
+		http.Error(w, "the player store does not keep a history of wins", http.StatusNotImplemented)
+		return
+	}
+
+	if err != nil {
+		storeError(w, "could not get league", err)
+		return
+	}
+
+	var body bytes.Buffer
+	json.NewEncoder(&body).Encode(league)
+
+	etag := fmt.Sprintf(`"%x"`, sha256.Sum256(body.Bytes()))
+
 	w.Header().Set("content-type", jsonContentType)
-	json.NewEncoder(w).Encode(p.store.GetLeague())
+	w.Header().Set("ETag", etag)
+
+	// Only the season's league is tracked for Last-Modified; windows are revalidated by ETag alone.
+	if windowed && matchesETag(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if !windowed {
+		modified := p.version.observe(etag, p.now())
+		w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+
+		if notModified(r, etag, modified) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Write(body.Bytes())
+}
+
+// leagueWindow reads the window of time a league is asked for from query. since and until
+// are dates or RFC 3339 times, and a date until includes the whole of that day. A period
+// of week or month starts at the beginning of the current calendar week or month in UTC,
+// and season asks for the current season's league, as does no window at all.
+func leagueWindow(query url.Values, now time.Time) (since, until time.Time, err error) {
+	period := query.Get("period")
+
+	if period != "" && (query.Get("since") != "" || query.Get("until") != "") {
+		return since, until, errors.New("period cannot be combined with since or until")
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch period {
+	case "", "season":
+	case "week":
+		since = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return since, until, nil
+	case "month":
+		since = today.AddDate(0, 0, 1-today.Day())
+		return since, until, nil
+	default:
+		return since, until, fmt.Errorf("unknown period %q, want week, month or season", period)
+	}
+
+	if since, _, err = parseWindowTime(query.Get("since")); err != nil {
+		return since, until, fmt.Errorf("problem parsing since, %v", err)
+	}
+
+	until, date, err := parseWindowTime(query.Get("until"))
+
+	if err != nil {
+		return since, until, fmt.Errorf("problem parsing until, %v", err)
+	}
+
+	if date {
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return since, until, nil
 }
 
+// parseWindowTime parses a date or RFC 3339 time, reporting whether it was a date.
+// An empty value is the zero time.
+func parseWindowTime(value string) (time.Time, bool, error) {
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// playersHandler serves /players/{name} and /players/{name}/balance. A name is a single
+// path segment, so slashes in names must be escaped.
 func (p *PlayerServer) playersHandler(w http.ResponseWriter, r *http.Request) {
-	player := strings.TrimPrefix(r.URL.Path, "/players/")
+	segments := strings.Split(strings.TrimPrefix(r.URL.EscapedPath(), "/players/"), "/")
+
+	if len(segments) > 2 || (len(segments) == 2 && segments[1] != "balance") {
+		http.NotFound(w, r)
+		return
+	}
+
+	player, err := url.PathUnescape(segments[0])
+
+	if err != nil || player == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(segments) == 2 {
+		p.balanceHandler(w, r, player)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodPost:
-		p.processWin(w, player)
+		p.processWin(w, r, player)
 	case http.MethodGet:
-		p.showScore(w, player)
+		p.showScore(w, r, player)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *PlayerServer) balanceHandler(w http.ResponseWriter, r *http.Request, player string) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
 	}
+
+	p.showAccount(w, r, player)
 }
 
-func (p *PlayerServer) showScore(w http.ResponseWriter, player string) {
-	score := p.store.GetPlayerScore(player)
+func (p *PlayerServer) showScore(w http.ResponseWriter, r *http.Request, player string) {
+	score, err := p.store.GetPlayerScore(r.Context(), player)
+
+	if err != nil {
+		storeError(w, fmt.Sprintf("could not get score for %s", player), err)
+		return
+	}
 
 	if score == 0 {
 		w.WriteHeader(http.StatusNotFound)
@@ -69,7 +326,185 @@ func (p *PlayerServer) showScore(w http.ResponseWriter, player string) {
 	fmt.Fprint(w, score)
 }
 
-func (p *PlayerServer) processWin(w http.ResponseWriter, player string) {
-	p.store.RecordWin(player)
+func (p *PlayerServer) showAccount(w http.ResponseWriter, r *http.Request, player string) {
+	bank, ok := p.store.(BankPlayerStore)
+
+	if !ok {
+		http.Error(w, "the player store does not keep chip balances", http.StatusNotImplemented)
+		return
+	}
+
+	account, err := bank.GetAccount(r.Context(), player)
+
+	if err != nil {
+		storeError(w, fmt.Sprintf("could not get balance for %s", player), err)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(account)
+}
+
+func (p *PlayerServer) processWin(w http.ResponseWriter, r *http.Request, player string) {
+	key := r.Header.Get("Idempotency-Key")
+	store, idempotent := p.store.(IdempotentPlayerStore)
+
+	recorded := true
+	var err error
+
+	if key != "" && idempotent {
+		recorded, err = store.RecordWinOnce(r.Context(), player, key)
+	} else {
+		err = p.store.RecordWin(r.Context(), player)
+	}
+
+	if errors.Is(err, ErrIdempotencyKeyReused) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err != nil {
+		storeError(w, fmt.Sprintf("could not record win for %s", player), err)
+		return
+	}
+
+	if !recorded {
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	p.winRecorded(r.Context(), player)
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// winRecorded tells league subscribers and webhooks about a win recorded through the server.
+func (p *PlayerServer) winRecorded(ctx context.Context, player string) {
+	p.changes.notify()
+
+	if p.webhooks == nil {
+		return
+	}
+
+	now := p.now()
+	p.webhooks.Send(WebhookEvent{Type: WebhookEventWin, Time: now, Player: player})
+
+	league, err := p.store.GetLeague(ctx)
+
+	if err != nil {
+		log.Printf("could not get league to check for a new leader, %v", err)
+		return
+	}
+
+	if previous, changed := p.leader.observe(league); changed {
+		p.webhooks.Send(WebhookEvent{Type: WebhookEventLeaderChanged, Time: now, Player: league[0].Name, Previous: previous})
+	}
+}
+
+func (p *PlayerServer) auditHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := p.auditLog.Entries(r.URL.Query().Get("player"))
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("content-type", jsonContentType)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// withOrigin tells the store who made each request, so the changes it makes are audited on their
+// behalf, and echoes the request ID so clients can find those changes in the audit log.
+func withOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := requestOrigin(r)
+		w.Header().Set("X-Request-ID", origin.RequestID)
+		next.ServeHTTP(w, r.WithContext(ContextWithOrigin(r.Context(), origin)))
+	})
+}
+
+// requestOrigin says who made r: the holder of its bearer token, or else its address. A name
+// given in an X-Actor header is kept only as a claim, as anyone can send one.
+func requestOrigin(r *http.Request) Origin {
+	origin := Origin{
+		Actor:        r.RemoteAddr,
+		ClaimedActor: r.Header.Get("X-Actor"),
+		Source:       AuditSourceHTTP,
+		RequestID:    r.Header.Get("X-Request-ID"),
+	}
+
+	if authorization := r.Header.Get("Authorization"); strings.HasPrefix(authorization, "Bearer ") {
+		origin.Actor = tokenActor(strings.TrimPrefix(authorization, "Bearer "))
+	}
+
+	if origin.RequestID == "" {
+		origin.RequestID = NewRequestID()
+	}
+
+	return origin
+}
+
+// storeError logs err and answers with a 5xx status, without leaking store internals to the client.
+func storeError(w http.ResponseWriter, message string, err error) {
+	log.Printf("%s, %v", message, err)
+
+	status := http.StatusInternalServerError
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		status = http.StatusGatewayTimeout
+	} else if errors.Is(err, ErrStoreUnavailable) {
+		status = http.StatusServiceUnavailable
+	}
+
+	http.Error(w, message, status)
+}
+
+// leagueVersion remembers the ETag of the league last served and when it changed.
+type leagueVersion struct {
+	mu       sync.Mutex
+	etag     string
+	modified time.Time
+}
+
+// observe records etag as the current version and returns when it was first seen. Last-Modified
+// only has whole seconds, so a version that changes within the second of the last one is moved on
+// to the next second, or clients holding the last one would be told it had not changed.
+func (v *leagueVersion) observe(etag string, now time.Time) time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if etag != v.etag {
+		modified := now.Truncate(time.Second)
+
+		if v.etag != "" && !modified.After(v.modified) {
+			modified = v.modified.Add(time.Second)
+		}
+
+		v.etag = etag
+		v.modified = modified
+	}
+
+	return v.modified
+}
+
+// notModified reports whether the conditional headers of r match the current league.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if r.Header.Get("If-None-Match") != "" {
+		return matchesETag(r, etag)
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+
+	return err == nil && !modified.After(since)
+}
+
+// matchesETag reports whether the If-None-Match header of r matches etag.
+func matchesETag(r *http.Request, etag string) bool {
+	for _, candidate := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -39,4 +39,52 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 		}
 		assertLeague(t, got, want)
 	})
+
+	t.Run("league etag changes after a win", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest())
+		etag := response.Header().Get("ETag")
+
+		server.ServeHTTP(httptest.NewRecorder(), newPostWinRequest(player))
+
+		request := newLeagueRequest()
+		request.Header.Set("If-None-Match", etag)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
+
+func TestRetriedWinsAreRecordedOnce(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[]`)
+	defer cleanDatabase()
+	store, err := NewFileSystemPlayerStore(database)
+
+	assertNoError(t, err)
+
+	server := NewPlayerServer(store)
+
+	for i := 0; i < 3; i++ {
+		request := newPostWinRequest("Pepper")
+		request.Header.Set("Idempotency-Key", "retry-me")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusAccepted)
+	}
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newGetScoreRequest("Pepper"))
+
+	assertResponseBody(t, response.Body.String(), "1")
+
+	request := newPostWinRequest("Cleo")
+	request.Header.Set("Idempotency-Key", "retry-me")
+	response = httptest.NewRecorder()
+
+	server.ServeHTTP(response, request)
+
+	assertStatus(t, response.Code, http.StatusUnprocessableEntity)
 }
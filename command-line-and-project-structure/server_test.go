@@ -1,22 +1,24 @@
 package poker
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestGETPlayers(t *testing.T) {
 	store := StubPlayerStore{
-		map[string]int{
+		scores: map[string]int{
 			"Pepper": 20,
 			"Floyd":  10,
 		},
-		nil,
-		nil,
 	}
 	server := NewPlayerServer(&store)
 
@@ -51,11 +53,7 @@ func TestGETPlayers(t *testing.T) {
 }
 
 func TestStoreWins(t *testing.T) {
-	store := StubPlayerStore{
-		map[string]int{},
-		nil,
-		nil,
-	}
+	store := StubPlayerStore{scores: map[string]int{}}
 	server := NewPlayerServer(&store)
 
 	t.Run("it records wins on POST", func(t *testing.T) {
@@ -71,6 +69,185 @@ func TestStoreWins(t *testing.T) {
 	})
 }
 
+type failingPlayerStore struct {
+	err error
+}
+
+func (f failingPlayerStore) GetPlayerScore(ctx context.Context, name string) (int, error) {
+	return 0, f.err
+}
+
+func (f failingPlayerStore) RecordWin(ctx context.Context, name string) error {
+	return f.err
+}
+
+func (f failingPlayerStore) GetLeague(ctx context.Context) (League, error) {
+	return nil, f.err
+}
+
+func TestStoreErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"failures are internal server errors", errors.New("disk full"), http.StatusInternalServerError},
+		{"outages are service unavailable", fmt.Errorf("dial redis, %w", ErrStoreUnavailable), http.StatusServiceUnavailable},
+		{"timeouts are gateway timeouts", context.DeadlineExceeded, http.StatusGatewayTimeout},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := NewPlayerServer(failingPlayerStore{c.err})
+
+			for _, request := range []*http.Request{newLeagueRequest(), newGetScoreRequest("Pepper"), newPostWinRequest("Pepper")} {
+				response := httptest.NewRecorder()
+
+				server.ServeHTTP(response, request)
+
+				assertStatus(t, response.Code, c.status)
+			}
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	store, auditLog := newAuditedStore(t, `[]`)
+	server := NewPlayerServer(store, WithAuditLog(auditLog))
+
+	request := newPostWinRequest("Pepper")
+	request.RemoteAddr = "10.0.0.1:1234"
+	request.Header.Set("X-Actor", "dealer")
+	request.Header.Set("X-Request-ID", "req-1")
+	response := serve(server, request)
+
+	request = newPostWinRequest("Floyd")
+	request.Header.Set("Authorization", "Bearer s3cret")
+	serve(server, request)
+
+	t.Run("it echoes the request ID", func(t *testing.T) {
+		if got := response.Header().Get("X-Request-ID"); got != "req-1" {
+			t.Errorf("got X-Request-ID %q want %q", got, "req-1")
+		}
+	})
+
+	t.Run("it records who made each win, keeping X-Actor only as a claim", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit?player=Pepper", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+
+		var got []AuditEntry
+		json.NewDecoder(response.Body).Decode(&got)
+
+		assertAuditPlayers(t, got, "Pepper")
+
+		entry := got[0]
+		if entry.Actor != "10.0.0.1:1234" || entry.ClaimedActor != "dealer" || entry.Source != AuditSourceHTTP || entry.RequestID != "req-1" || entry.Action != AuditActionWin {
+			t.Errorf("unexpected audit entry %+v", entry)
+		}
+	})
+
+	t.Run("it records the holder of a bearer token without the token", func(t *testing.T) {
+		entries, err := auditLog.Entries("Floyd")
+		assertNoError(t, err)
+		assertAuditPlayers(t, entries, "Floyd")
+
+		if entries[0].Actor != tokenActor("s3cret") {
+			t.Errorf("got actor %q want %q", entries[0].Actor, tokenActor("s3cret"))
+		}
+	})
+
+	t.Run("it returns every entry without a player", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/audit", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got []AuditEntry
+		json.NewDecoder(response.Body).Decode(&got)
+
+		assertAuditPlayers(t, got, "Pepper", "Floyd")
+	})
+}
+
+func TestBalance(t *testing.T) {
+	t.Run("it returns the player's account as JSON", func(t *testing.T) {
+		store := StubPlayerStore{ledger: Ledger{Deposit("Pepper", 100), BuyIn("Pepper", "friday", 30), Deposit("Floyd", 5)}}
+		server := NewPlayerServer(&store)
+
+		request, _ := http.NewRequest(http.MethodGet, "/players/Pepper/balance", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, jsonContentType)
+
+		var got Account
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse account %q, %v", response.Body, err)
+		}
+
+		if got.Player != "Pepper" || got.Balance != 70 || len(got.Transactions) != 2 {
+			t.Errorf("unexpected account %+v", got)
+		}
+	})
+
+	t.Run("it returns 501 for stores without a bank", func(t *testing.T) {
+		server := NewPlayerServer(failingPlayerStore{errors.New("disk full")})
+
+		request, _ := http.NewRequest(http.MethodGet, "/players/Pepper/balance", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
+
+	t.Run("it only allows GET", func(t *testing.T) {
+		store := StubPlayerStore{}
+		server := NewPlayerServer(&store)
+
+		request, _ := http.NewRequest(http.MethodPost, "/players/Pepper/balance", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusMethodNotAllowed)
+
+		if len(store.winCalls) != 0 {
+			t.Errorf("got wins recorded %q want none", store.winCalls)
+		}
+	})
+
+	t.Run("it does not accept names with slashes in them", func(t *testing.T) {
+		store := StubPlayerStore{ledger: Ledger{Deposit("a/b", 100)}}
+		server := NewPlayerServer(&store)
+
+		for _, path := range []string{"/players/a/b/balance", "/players/a/b", "/players/Pepper/balance/"} {
+			request, _ := http.NewRequest(http.MethodGet, path, nil)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			if response.Code != http.StatusNotFound {
+				t.Errorf("got status %d for %s want %d", response.Code, path, http.StatusNotFound)
+			}
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/players/a%2Fb/balance", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+	})
+}
+
 func TestLeague(t *testing.T) {
 
 	t.Run("it returns the league table as JSON", func(t *testing.T) {
@@ -80,7 +257,7 @@ func TestLeague(t *testing.T) {
 			{"Tiest", 14},
 		}
 
-		store := StubPlayerStore{nil, nil, wantedLeague}
+		store := StubPlayerStore{league: wantedLeague}
 		server := NewPlayerServer(&store)
 
 		request := newLeagueRequest()
@@ -95,6 +272,160 @@ func TestLeague(t *testing.T) {
 		assertContentType(t, response, jsonContentType)
 
 	})
+
+	t.Run("it returns 304 when the league has not changed", func(t *testing.T) {
+		store := StubPlayerStore{league: []Player{{"Cleo", 32}}}
+		server := NewPlayerServer(&store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest())
+		etag := response.Header().Get("ETag")
+
+		if etag == "" {
+			t.Fatal("expected an ETag header but got none")
+		}
+
+		if response.Header().Get("Last-Modified") == "" {
+			t.Error("expected a Last-Modified header but got none")
+		}
+
+		request := newLeagueRequest()
+		request.Header.Set("If-None-Match", etag)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotModified)
+		assertResponseBody(t, response.Body.String(), "")
+	})
+
+	t.Run("it returns the league again once it has changed", func(t *testing.T) {
+		store := StubPlayerStore{league: []Player{{"Cleo", 32}}}
+		server := NewPlayerServer(&store)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest())
+		etag := response.Header().Get("ETag")
+
+		store.league = []Player{{"Cleo", 33}}
+
+		request := newLeagueRequest()
+		request.Header.Set("If-None-Match", etag)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertLeague(t, getLeagueFromResponse(t, response.Body), store.league)
+
+		if response.Header().Get("ETag") == etag {
+			t.Errorf("expected ETag to change from %s", etag)
+		}
+	})
+}
+
+func TestLeagueLastModified(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 9, 0, 0, 100*int(time.Millisecond), time.UTC)
+
+	store := StubPlayerStore{league: []Player{{"Cleo", 32}}}
+	server := NewPlayerServer(&store)
+	server.now = func() time.Time { return now }
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, newLeagueRequest())
+	modified := response.Header().Get("Last-Modified")
+
+	t.Run("it returns 304 when the league has not changed since", func(t *testing.T) {
+		request := newLeagueRequest()
+		request.Header.Set("If-Modified-Since", modified)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotModified)
+	})
+
+	t.Run("it returns a league that changed within the same second", func(t *testing.T) {
+		now = now.Add(500 * time.Millisecond)
+		store.league = []Player{{"Cleo", 33}}
+
+		request := newLeagueRequest()
+		request.Header.Set("If-Modified-Since", modified)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertLeague(t, getLeagueFromResponse(t, response.Body), store.league)
+
+		if got := response.Header().Get("Last-Modified"); got == modified {
+			t.Errorf("expected Last-Modified to move on from %s", modified)
+		}
+	})
+}
+
+func TestLeagueWindows(t *testing.T) {
+	wednesday := time.Date(2026, time.October, 14, 12, 0, 0, 0, time.UTC)
+
+	store := StubPlayerStore{
+		league: []Player{{"Chris", 2}, {"Cleo", 1}, {"Tiest", 1}},
+		wins: []Win{
+			{"Tiest", time.Date(2026, time.September, 30, 18, 0, 0, 0, time.UTC)},
+			{"Cleo", time.Date(2026, time.October, 1, 9, 0, 0, 0, time.UTC)},
+			{"Chris", time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)},
+			{"Chris", wednesday},
+		},
+	}
+	server := NewPlayerServer(&store)
+	server.now = func() time.Time { return wednesday }
+
+	cases := []struct {
+		query string
+		want  []Player
+	}{
+		{"period=week", []Player{{"Chris", 2}}},
+		{"period=month", []Player{{"Chris", 2}, {"Cleo", 1}}},
+		{"period=season", store.league},
+		{"since=2026-09-30&until=2026-10-01", []Player{{"Cleo", 1}, {"Tiest", 1}}},
+		{"since=2026-10-01T12:00:00Z", []Player{{"Chris", 2}}},
+		{"until=2026-10-12T00:00:00Z", []Player{{"Cleo", 1}, {"Tiest", 1}}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newLeagueWindowRequest(c.query))
+
+			assertStatus(t, response.Code, http.StatusOK)
+			assertLeague(t, getLeagueFromResponse(t, response.Body), c.want)
+		})
+	}
+
+	for _, query := range []string{"period=fortnight", "period=week&since=2026-10-01", "since=yesterday"} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newLeagueWindowRequest(query))
+
+			assertStatus(t, response.Code, http.StatusBadRequest)
+		})
+	}
+
+	t.Run("returns 304 for an unchanged window", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueWindowRequest("period=week"))
+
+		request := newLeagueWindowRequest("period=week")
+		request.Header.Set("If-None-Match", response.Header().Get("ETag"))
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusNotModified)
+	})
+
+	t.Run("returns 501 for stores without a history", func(t *testing.T) {
+		server := NewPlayerServer(failingPlayerStore{errors.New("disk full")})
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueWindowRequest("period=week"))
+
+		assertStatus(t, response.Code, http.StatusNotImplemented)
+	})
 }
 
 func assertContentType(t testing.TB, response *httptest.ResponseRecorder, want string) {
@@ -134,6 +465,11 @@ func newLeagueRequest() *http.Request {
 	return req
 }
 
+func newLeagueWindowRequest(query string) *http.Request {
+	req, _ := http.NewRequest(http.MethodGet, "/league?"+query, nil)
+	return req
+}
+
 func newGetScoreRequest(name string) *http.Request {
 	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/players/%s", name), nil)
 	return req
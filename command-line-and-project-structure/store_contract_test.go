@@ -0,0 +1,72 @@
+package poker_test
+
+import (
+	poker "command-line-and-project-structure"
+	"command-line-and-project-structure/redistest"
+	"command-line-and-project-structure/storetest"
+	"path/filepath"
+	"testing"
+)
+
+func TestStubPlayerStoreContract(t *testing.T) {
+	storetest.RunPlayerStoreContract(t, func(t *testing.T) storetest.Subject {
+		return storetest.Subject{Store: &poker.StubPlayerStore{}}
+	})
+}
+
+func TestFileSystemPlayerStoreContract(t *testing.T) {
+	storetest.RunPlayerStoreContract(t, func(t *testing.T) storetest.Subject {
+		path := filepath.Join(t.TempDir(), "game.db.json")
+
+		return storetest.Subject{
+			Store:  openFileSystemStore(t, path),
+			Reopen: func() poker.PlayerStore { return openFileSystemStore(t, path) },
+		}
+	})
+}
+
+func TestEncryptedFileSystemPlayerStoreContract(t *testing.T) {
+	key, err := poker.GenerateEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storetest.RunPlayerStoreContract(t, func(t *testing.T) storetest.Subject {
+		path := filepath.Join(t.TempDir(), "game.db.json")
+
+		return storetest.Subject{
+			Store:  openFileSystemStore(t, path, poker.WithEncryptionKey(key)),
+			Reopen: func() poker.PlayerStore { return openFileSystemStore(t, path, poker.WithEncryptionKey(key)) },
+		}
+	})
+}
+
+func TestRedisPlayerStoreContract(t *testing.T) {
+	storetest.RunPlayerStoreContract(t, func(t *testing.T) storetest.Subject {
+		server, err := redistest.NewServer()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { server.Close() })
+
+		open := func() poker.PlayerStore {
+			store := poker.NewRedisPlayerStore(server.Addr(), poker.DefaultRedisLeagueKey)
+			t.Cleanup(func() { store.Close() })
+			return store
+		}
+
+		return storetest.Subject{Store: open(), Reopen: open}
+	})
+}
+
+func openFileSystemStore(t *testing.T, path string, options ...poker.FileSystemPlayerStoreOption) poker.PlayerStore {
+	t.Helper()
+
+	store, close, err := poker.FileSystemFileStoreFromFile(path, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(close)
+
+	return store
+}
@@ -0,0 +1,178 @@
+// Package storetest is a contract test suite every poker.PlayerStore should pass.
+//
+// The http-server and json-routing-embedding chapters are separate modules whose stores predate
+// contexts and errors, so they cannot import it. Each keeps a copy of the suite for the parts of
+// the contract its store has, which should be changed along with this one.
+package storetest
+
+import (
+	poker "command-line-and-project-structure"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// Subject is a store under test.
+type Subject struct {
+	// Store is an empty store.
+	Store poker.PlayerStore
+
+	// Reopen returns a new store over the same storage as Store, to check wins persist.
+	// Stores that only keep players in memory leave it nil.
+	Reopen func() poker.PlayerStore
+}
+
+// Factory creates a new, empty Subject for each contract test, registering any clean up with t.
+type Factory func(t *testing.T) Subject
+
+// RunPlayerStoreContract runs the PlayerStore contract against stores made by factory.
+func RunPlayerStoreContract(t *testing.T, factory Factory) {
+	t.Helper()
+
+	t.Run("unknown players have no wins", func(t *testing.T) {
+		store := factory(t).Store
+
+		assertScore(t, store, "Apollo", 0)
+	})
+
+	t.Run("records wins for new players", func(t *testing.T) {
+		store := factory(t).Store
+
+		recordWins(t, store, "Pepper", 1)
+
+		assertScore(t, store, "Pepper", 1)
+		assertLeague(t, store, []poker.Player{{Name: "Pepper", Wins: 1}})
+	})
+
+	t.Run("records wins for existing players", func(t *testing.T) {
+		store := factory(t).Store
+
+		recordWins(t, store, "Chris", 3)
+		recordWins(t, store, "Cleo", 2)
+
+		assertScore(t, store, "Chris", 3)
+		assertScore(t, store, "Cleo", 2)
+	})
+
+	t.Run("league is ordered by wins", func(t *testing.T) {
+		store := factory(t).Store
+
+		recordWins(t, store, "Cleo", 1)
+		recordWins(t, store, "Tiest", 3)
+		recordWins(t, store, "Chris", 2)
+
+		assertLeague(t, store, []poker.Player{
+			{Name: "Tiest", Wins: 3},
+			{Name: "Chris", Wins: 2},
+			{Name: "Cleo", Wins: 1},
+		})
+	})
+
+	t.Run("league orders players with the same wins by name", func(t *testing.T) {
+		store := factory(t).Store
+
+		for _, player := range []string{"Pepper", "Chris", "Tiest", "Cleo"} {
+			recordWins(t, store, player, 1)
+		}
+		recordWins(t, store, "Tiest", 1)
+
+		assertLeague(t, store, []poker.Player{
+			{Name: "Tiest", Wins: 2},
+			{Name: "Chris", Wins: 1},
+			{Name: "Cleo", Wins: 1},
+			{Name: "Pepper", Wins: 1},
+		})
+	})
+
+	t.Run("empty league", func(t *testing.T) {
+		store := factory(t).Store
+
+		assertLeague(t, store, []poker.Player{})
+	})
+
+	t.Run("wins persist across reopening", func(t *testing.T) {
+		subject := factory(t)
+
+		if subject.Reopen == nil {
+			t.Skip("store does not persist")
+		}
+
+		recordWins(t, subject.Store, "Chris", 2)
+		recordWins(t, subject.Store, "Cleo", 1)
+
+		reopened := subject.Reopen()
+
+		assertScore(t, reopened, "Chris", 2)
+		assertLeague(t, reopened, []poker.Player{
+			{Name: "Chris", Wins: 2},
+			{Name: "Cleo", Wins: 1},
+		})
+	})
+
+	t.Run("records concurrent wins", func(t *testing.T) {
+		store := factory(t).Store
+		players := []string{"Chris", "Cleo", "Pepper"}
+		wantedWins := 20
+
+		var wg sync.WaitGroup
+
+		for _, player := range players {
+			for i := 0; i < wantedWins; i++ {
+				wg.Add(1)
+				go func(player string) {
+					defer wg.Done()
+					if err := store.RecordWin(context.Background(), player); err != nil {
+						t.Errorf("could not record win for %s, %v", player, err)
+					}
+				}(player)
+			}
+		}
+
+		wg.Wait()
+
+		for _, player := range players {
+			assertScore(t, store, player, wantedWins)
+		}
+	})
+}
+
+func recordWins(t testing.TB, store poker.PlayerStore, player string, wins int) {
+	t.Helper()
+
+	for i := 0; i < wins; i++ {
+		if err := store.RecordWin(context.Background(), player); err != nil {
+			t.Fatalf("could not record win for %s, %v", player, err)
+		}
+	}
+}
+
+func assertScore(t testing.TB, store poker.PlayerStore, player string, want int) {
+	t.Helper()
+
+	got, err := store.GetPlayerScore(context.Background(), player)
+
+	if err != nil {
+		t.Fatalf("could not get score for %s, %v", player, err)
+	}
+
+	if got != want {
+		t.Errorf("got %d wins for %s want %d", got, player, want)
+	}
+}
+
+// assertLeague checks the league is exactly the players wanted, most wins first and players
+// with the same number of wins by name.
+func assertLeague(t testing.TB, store poker.PlayerStore, want []poker.Player) {
+	t.Helper()
+
+	got, err := store.GetLeague(context.Background())
+
+	if err != nil {
+		t.Fatalf("could not get league, %v", err)
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got league %v want %v", got, want)
+	}
+}
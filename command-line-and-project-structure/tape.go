@@ -1,15 +1,127 @@
 package poker
 
 import (
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 )
 
+// tempFile is the part of *os.File that tape writes through, so tests can inject failures.
+type tempFile interface {
+	io.Writer
+	Chmod(mode os.FileMode) error
+	Sync() error
+	Close() error
+	Name() string
+}
+
+// tape replaces the whole of a file on every write. Each write goes to a sibling
+// temp file that is synced and renamed over the original, so readers only ever
+// see a complete file even if the process dies part way through. The temp file is
+// given the original's permissions, so whoever could share the file still can.
 type tape struct {
-	file *os.File
+	file       *os.File
+	owned      bool
+	createTemp func(dir, pattern string) (tempFile, error)
+}
+
+func newTape(file *os.File) *tape {
+	return &tape{file: file, createTemp: osCreateTemp}
+}
+
+func osCreateTemp(dir, pattern string) (tempFile, error) {
+	return os.CreateTemp(dir, pattern)
 }
 
 func (t *tape) Write(p []byte) (n int, err error) {
-	t.file.Truncate(0)
-	t.file.Seek(0, 0)
-	return t.file.Write(p)
+	path := t.file.Name()
+
+	info, err := t.file.Stat()
+
+	if err != nil {
+		return 0, fmt.Errorf("problem getting file info from file %s, %v", path, err)
+	}
+
+	tmp, err := t.createTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+
+	if err != nil {
+		return 0, fmt.Errorf("problem creating temp file for %s, %v", path, err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	err = tmp.Chmod(info.Mode().Perm())
+
+	if err == nil {
+		n, err = tmp.Write(p)
+	}
+
+	if err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("problem writing temp file for %s, %v", path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("problem replacing %s, %v", path, err)
+	}
+
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return 0, fmt.Errorf("problem syncing directory of %s, %v", path, err)
+	}
+
+	return n, t.reopen()
+}
+
+// refresh reopens the file if another process has replaced it, reporting whether it did.
+// The tape keeps the file it last read open, so its inode cannot be reused by a replacement.
+func (t *tape) refresh() (bool, error) {
+	onDisk, err := os.Stat(t.file.Name())
+
+	if err != nil {
+		return false, fmt.Errorf("problem getting file info from file %s, %v", t.file.Name(), err)
+	}
+
+	open, err := t.file.Stat()
+
+	if err != nil {
+		return false, fmt.Errorf("problem getting file info from file %s, %v", t.file.Name(), err)
+	}
+
+	if os.SameFile(onDisk, open) {
+		return false, nil
+	}
+
+	return true, t.reopen()
+}
+
+// reopen points the tape at whatever file is now at its path.
+func (t *tape) reopen() error {
+	file, err := os.OpenFile(t.file.Name(), os.O_RDWR, 0666)
+
+	if err != nil {
+		return fmt.Errorf("problem reopening %s, %v", t.file.Name(), err)
+	}
+
+	t.Close()
+	t.file = file
+	t.owned = true
+
+	return nil
+}
+
+// Close closes the file if the tape opened it itself.
+func (t *tape) Close() error {
+	if !t.owned {
+		return nil
+	}
+
+	return t.file.Close()
 }
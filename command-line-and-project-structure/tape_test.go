@@ -1,25 +1,183 @@
 package poker
 
 import (
+	"context"
+	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
 func TestTape_Write(t *testing.T) {
-	file, clean := createTempFile(t, "12345")
-	defer clean()
+	t.Run("replaces the file contents", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
 
-	tape := &tape{file}
+		tape := newTape(file)
+		defer tape.Close()
 
-	tape.Write([]byte("abc"))
+		_, err := tape.Write([]byte("abc"))
+		assertNoError(t, err)
 
-	file.Seek(0, 0)
-	newFileContents, _ := io.ReadAll(file)
+		assertFileContents(t, tape.file, "abc")
+		assertOnDisk(t, file.Name(), "abc")
+	})
 
-	got := string(newFileContents)
-	want := "abc"
+	t.Run("keeps the permissions of the file", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
 
-	if got != want {
-		t.Errorf("got %q want %q", got, want)
+		assertNoError(t, os.Chmod(file.Name(), 0664))
+
+		tape := newTape(file)
+		defer tape.Close()
+
+		_, err := tape.Write([]byte("abc"))
+		assertNoError(t, err)
+
+		info, err := os.Stat(file.Name())
+		assertNoError(t, err)
+
+		if got := info.Mode().Perm(); got != 0664 {
+			t.Errorf("got permissions %v want %v", got, os.FileMode(0664))
+		}
+	})
+
+	t.Run("leaves the file alone when writing fails", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
+
+		tape := newTape(file)
+		failing := &failingTempFile{writeErr: errors.New("disk full")}
+		tape.createTemp = failing.create
+
+		_, err := tape.Write([]byte("abc"))
+
+		assertError(t, err)
+		assertOnDisk(t, file.Name(), "12345")
+		assertNoFile(t, failing.Name())
+	})
+
+	t.Run("leaves the file alone when syncing fails", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
+
+		tape := newTape(file)
+		failing := &failingTempFile{syncErr: errors.New("io error")}
+		tape.createTemp = failing.create
+
+		_, err := tape.Write([]byte("abc"))
+
+		assertError(t, err)
+		assertOnDisk(t, file.Name(), "12345")
+	})
+
+	t.Run("leaves the file alone when the temp file cannot be created", func(t *testing.T) {
+		file, clean := createTempFile(t, "12345")
+		defer clean()
+
+		tape := newTape(file)
+		tape.createTemp = func(dir, pattern string) (tempFile, error) {
+			return nil, errors.New("read-only file system")
+		}
+
+		_, err := tape.Write([]byte("abc"))
+
+		assertError(t, err)
+		assertOnDisk(t, file.Name(), "12345")
+	})
+}
+
+func TestFileSystemStoreWriteFailures(t *testing.T) {
+	database, cleanDatabase := createTempFile(t, `[
+		{"Name": "Cleo", "Wins": 10}]`)
+	defer cleanDatabase()
+
+	store, err := NewFileSystemPlayerStore(database)
+	assertNoError(t, err)
+	defer store.Close()
+
+	store.tape.createTemp = (&failingTempFile{writeErr: errors.New("disk full")}).create
+
+	t.Run("RecordWin returns the error", func(t *testing.T) {
+		assertError(t, store.RecordWin(context.Background(), "Cleo"))
+	})
+
+	t.Run("the failed win is not kept", func(t *testing.T) {
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 10)
+	})
+
+	t.Run("idempotency keys of failed wins can be retried", func(t *testing.T) {
+		_, err := store.RecordWinOnce(context.Background(), "Cleo", "abc")
+		assertError(t, err)
+
+		store.tape.createTemp = osCreateTemp
+
+		assertRecorded(t, recordWinOnce(t, store, "Cleo", "abc"), true)
+		assertScoreEquals(t, getScore(t, store, "Cleo"), 11)
+	})
+}
+
+// failingTempFile is a temp file whose writes or syncs fail.
+type failingTempFile struct {
+	*os.File
+	writeErr error
+	syncErr  error
+}
+
+func (f *failingTempFile) create(dir, pattern string) (tempFile, error) {
+	file, err := os.CreateTemp(dir, pattern)
+	f.File = file
+	return f, err
+}
+
+func (f *failingTempFile) Write(p []byte) (int, error) {
+	if f.writeErr != nil {
+		return 0, f.writeErr
+	}
+	return f.File.Write(p)
+}
+
+func (f *failingTempFile) Sync() error {
+	if f.syncErr != nil {
+		return f.syncErr
+	}
+	return f.File.Sync()
+}
+
+func (f *failingTempFile) Name() string {
+	if f.File == nil {
+		return filepath.Join(os.TempDir(), "never-created")
+	}
+	return f.File.Name()
+}
+
+func assertOnDisk(t testing.TB, path, want string) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	assertNoError(t, err)
+	defer file.Close()
+
+	got, _ := io.ReadAll(file)
+
+	if string(got) != want {
+		t.Errorf("got %q on disk want %q", got, want)
+	}
+}
+
+func assertNoFile(t testing.TB, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s to have been removed", path)
+	}
+}
+
+func assertError(t testing.TB, err error) {
+	t.Helper()
+	if err == nil {
+		t.Error("expected an error but didn't get one")
 	}
 }
@@ -1,26 +1,85 @@
 package poker
 
 import (
+	"context"
+	"sync"
 	"testing"
+	"time"
 )
 
+// StubPlayerStore keeps scores in memory and remembers every call to RecordWin.
+// A league given up front is returned as is, otherwise the league is built from the scores.
+// Windowed leagues are built from wins, which RecordWin adds to, and accounts from the ledger.
 type StubPlayerStore struct {
+	mu       sync.Mutex
 	scores   map[string]int
 	winCalls []string
 	league   []Player
+	wins     []Win
+	ledger   Ledger
 }
 
-func (s *StubPlayerStore) GetPlayerScore(name string) int {
+func (s *StubPlayerStore) GetPlayerScore(ctx context.Context, name string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	score := s.scores[name]
-	return score
+	return score, nil
 }
 
-func (s *StubPlayerStore) RecordWin(name string) {
+func (s *StubPlayerStore) RecordWin(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.scores == nil {
+		s.scores = map[string]int{}
+	}
+
+	s.scores[name]++
 	s.winCalls = append(s.winCalls, name)
+	s.wins = append(s.wins, Win{name, time.Now()})
+	return nil
 }
 
-func (s *StubPlayerStore) GetLeague() League {
-	return s.league
+func (s *StubPlayerStore) GetLeague(ctx context.Context) (League, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.league != nil {
+		return s.league, nil
+	}
+
+	league := League{}
+	for name, wins := range s.scores {
+		league = append(league, Player{name, wins})
+	}
+
+	sortLeague(league)
+
+	return league, nil
+}
+
+func (s *StubPlayerStore) GetLeagueBetween(ctx context.Context, since, until time.Time) (League, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return leagueBetween(s.wins, since, until), nil
+}
+
+func (s *StubPlayerStore) RecordTransaction(ctx context.Context, t Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ledger, err := s.ledger.record(t)
+	s.ledger = ledger
+	return err
+}
+
+func (s *StubPlayerStore) GetAccount(ctx context.Context, player string) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ledger.account(player), nil
 }
 
 func AssertPlayerWin(t testing.TB, store *StubPlayerStore, winner string) {
@@ -0,0 +1,158 @@
+package poker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SelfSignedValidity is how long a generated self-signed certificate is valid for.
+const SelfSignedValidity = 365 * 24 * time.Hour
+
+// DefaultHSTSMaxAge is how long browsers are told to only reach the server over HTTPS.
+const DefaultHSTSMaxAge = 365 * 24 * time.Hour
+
+// GenerateSelfSignedCertificate creates a certificate for hosts, which may be names or IP
+// addresses, signed by its own ECDSA key and valid from now for validFor. Both are PEM encoded.
+func GenerateSelfSignedCertificate(hosts []string, now time.Time, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("a certificate needs at least one host")
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem generating key, %v", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem generating serial number, %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Poker league"}, CommonName: hosts[0]},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem creating certificate, %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem encoding key, %v", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+// SelfSignedCertificateFromFiles loads the certificate and key in certFile and keyFile,
+// generating a self-signed pair for hosts first if either is missing or the certificate has
+// expired, so local servers keep the certificate a browser has been told to trust.
+func SelfSignedCertificateFromFiles(certFile, keyFile string, hosts []string) (tls.Certificate, error) {
+	if cert, err := tls.LoadX509KeyPair(certFile, keyFile); err == nil {
+		if leaf, err := x509.ParseCertificate(cert.Certificate[0]); err == nil && time.Now().Before(leaf.NotAfter) {
+			return cert, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return tls.Certificate{}, fmt.Errorf("problem loading certificate from %s and %s, %v", certFile, keyFile, err)
+	}
+
+	certPEM, keyPEM, err := GenerateSelfSignedCertificate(hosts, time.Now(), SelfSignedValidity)
+
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	if err := os.WriteFile(keyFile, keyPEM, 0600); err != nil {
+		return tls.Certificate{}, fmt.Errorf("problem writing key to %s, %v", keyFile, err)
+	}
+
+	if err := os.WriteFile(certFile, certPEM, 0644); err != nil {
+		return tls.Certificate{}, fmt.Errorf("problem writing certificate to %s, %v", certFile, err)
+	}
+
+	return tls.X509KeyPair(certPEM, keyPEM)
+}
+
+// WithHSTS tells browsers to only reach the server over HTTPS for maxAge, on every response
+// served over TLS. Plain HTTP responses are left alone, as browsers ignore the header there.
+func WithHSTS(maxAge time.Duration) PlayerServerOption {
+	return func(p *PlayerServer) {
+		p.hstsMaxAge = maxAge
+	}
+}
+
+// hsts adds the Strict-Transport-Security header to responses next serves over TLS.
+func hsts(next http.Handler, maxAge time.Duration) http.Handler {
+	value := "max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RedirectToHTTPS redirects every request to the same host and path over HTTPS on the port
+// of httpsAddr. The redirect is permanent and keeps the method, so recorded wins are not lost.
+func RedirectToHTTPS(httpsAddr string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddr)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		} else {
+			host = strings.Trim(host, "[]")
+		}
+
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		target := url.URL{Scheme: "https", Host: host, Path: r.URL.Path, RawQuery: r.URL.RawQuery}
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,145 @@
+package poker
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHSTS(t *testing.T) {
+	store := StubPlayerStore{scores: map[string]int{"Pepper": 20}}
+	handler := NewPlayerServer(&store, WithHSTS(DefaultHSTSMaxAge))
+
+	t.Run("is sent over TLS", func(t *testing.T) {
+		server := httptest.NewTLSServer(handler)
+		defer server.Close()
+
+		response, err := server.Client().Get(server.URL + "/players/Pepper")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		assertStatus(t, response.StatusCode, http.StatusOK)
+
+		if got, want := response.Header.Get("Strict-Transport-Security"), "max-age=31536000"; got != want {
+			t.Errorf("got Strict-Transport-Security %q want %q", got, want)
+		}
+	})
+
+	t.Run("is not sent over plain HTTP", func(t *testing.T) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		response, err := server.Client().Get(server.URL + "/players/Pepper")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		if got := response.Header.Get("Strict-Transport-Security"); got != "" {
+			t.Errorf("got Strict-Transport-Security %q over plain HTTP", got)
+		}
+	})
+}
+
+func TestSelfSignedCertificate(t *testing.T) {
+	t.Run("is trusted for its hosts by a client that trusts it", func(t *testing.T) {
+		certPEM, keyPEM, err := GenerateSelfSignedCertificate([]string{"localhost", "127.0.0.1"}, time.Now(), time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		store := StubPlayerStore{scores: map[string]int{"Pepper": 20}}
+		server := httptest.NewUnstartedServer(NewPlayerServer(&store))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+		server.StartTLS()
+		defer server.Close()
+
+		roots := x509.NewCertPool()
+		roots.AppendCertsFromPEM(certPEM)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+		response, err := client.Get(server.URL + "/players/Pepper")
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+
+		assertStatus(t, response.StatusCode, http.StatusOK)
+	})
+
+	t.Run("needs a host", func(t *testing.T) {
+		if _, _, err := GenerateSelfSignedCertificate(nil, time.Now(), time.Hour); err == nil {
+			t.Error("expected an error for a certificate without hosts")
+		}
+	})
+
+	t.Run("is generated once and kept in its files", func(t *testing.T) {
+		dir := t.TempDir()
+		certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+
+		first, err := SelfSignedCertificateFromFiles(certFile, keyFile, []string{"localhost"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := SelfSignedCertificateFromFiles(certFile, keyFile, []string{"localhost"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(first.Certificate[0], second.Certificate[0]) {
+			t.Error("certificate was generated again instead of loaded")
+		}
+
+		info, err := os.Stat(keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("got key file permissions %o want 600", perm)
+		}
+	})
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	cases := []struct {
+		httpsAddr string
+		host      string
+		target    string
+		want      string
+	}{
+		{":443", "example.com", "/league?period=week", "https://example.com/league?period=week"},
+		{":443", "example.com:80", "/players/Pepper", "https://example.com/players/Pepper"},
+		{":4443", "localhost:4080", "/league", "https://localhost:4443/league"},
+		{":4443", "[::1]:4080", "/league", "https://[::1]:4443/league"},
+		{":443", "[::1]:80", "/league", "https://[::1]/league"},
+	}
+
+	for _, c := range cases {
+		request := httptest.NewRequest(http.MethodPost, c.target, nil)
+		request.Host = c.host
+		response := httptest.NewRecorder()
+
+		RedirectToHTTPS(c.httpsAddr).ServeHTTP(response, request)
+
+		assertStatus(t, response.Code, http.StatusPermanentRedirect)
+
+		if got := response.Header().Get("Location"); got != c.want {
+			t.Errorf("redirecting %s%s to %s got %q want %q", c.host, c.target, c.httpsAddr, got, c.want)
+		}
+	}
+}
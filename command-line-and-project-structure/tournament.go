@@ -0,0 +1,462 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// TournamentFormat is how the matches of a tournament are drawn.
+type TournamentFormat string
+
+const (
+	// SingleElimination is a knockout bracket; top seeds get byes when the field is not a power of two.
+	SingleElimination TournamentFormat = "single-elimination"
+	// RoundRobin has every player meet every other once.
+	RoundRobin TournamentFormat = "round-robin"
+	// Swiss pairs players with similar records each round, without rematches where possible.
+	Swiss TournamentFormat = "swiss"
+)
+
+// Errors returned when a match result cannot be recorded.
+var (
+	ErrUnknownMatch   = errors.New("no such match")
+	ErrMatchNotReady  = errors.New("match is waiting for its players")
+	ErrMatchDecided   = errors.New("match already has a winner")
+	ErrNotInMatch     = errors.New("winner is not playing in the match")
+	ErrTooFewEntrants = errors.New("a tournament needs at least two players")
+)
+
+// Match is a game between two players. A missing player is a bye, which the other wins.
+type Match struct {
+	ID      int       `json:"id"`
+	Round   int       `json:"round"`
+	Players [2]string `json:"players"`
+	Winner  string    `json:"winner,omitempty"`
+}
+
+// Decided reports whether the match has a winner.
+func (m Match) Decided() bool {
+	return m.Winner != ""
+}
+
+// Tournament is a competition between players seeded from the league, best first.
+type Tournament struct {
+	ID      int              `json:"id"`
+	Name    string           `json:"name"`
+	Format  TournamentFormat `json:"format"`
+	Players []string         `json:"players"`
+	Rounds  int              `json:"rounds"`
+	Matches []Match          `json:"matches"`
+}
+
+// NewTournament draws a tournament between players, seeded by their wins in league.
+// Players not in the league are seeded last, in the order given. With no players
+// every player in the league is entered.
+func NewTournament(name string, format TournamentFormat, league League, players []string) (*Tournament, error) {
+	if len(players) == 0 {
+		for _, p := range league {
+			players = append(players, p.Name)
+		}
+	}
+
+	seeds, err := seedPlayers(league, players)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tournament{Name: name, Format: format, Players: seeds}
+
+	switch format {
+	case SingleElimination:
+		t.drawBracket()
+	case RoundRobin:
+		t.drawRoundRobin()
+	case Swiss:
+		t.Rounds = swissRounds(len(seeds))
+		t.drawSwissRound(1)
+	default:
+		return nil, fmt.Errorf("unknown tournament format %q", format)
+	}
+
+	return t, nil
+}
+
+// RecordResult records winner as the winner of the match with id, drawing
+// any matches that were waiting on it.
+func (t *Tournament) RecordResult(id int, winner string) error {
+	if id < 1 || id > len(t.Matches) {
+		return ErrUnknownMatch
+	}
+
+	match := &t.Matches[id-1]
+
+	switch {
+	case match.Decided():
+		return ErrMatchDecided
+	case match.Players[0] == "" || match.Players[1] == "":
+		return ErrMatchNotReady
+	case winner != match.Players[0] && winner != match.Players[1]:
+		return ErrNotInMatch
+	}
+
+	match.Winner = winner
+	t.advance(*match)
+
+	return nil
+}
+
+// Finished reports whether every match has been played.
+func (t *Tournament) Finished() bool {
+	if t.Format == Swiss && t.currentRound() < t.Rounds {
+		return false
+	}
+
+	for _, m := range t.Matches {
+		if !m.Decided() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Standings counts the matches each player has won, byes included, most first
+// and then by seed.
+func (t *Tournament) Standings() League {
+	wins := map[string]int{}
+
+	for _, m := range t.Matches {
+		if m.Decided() {
+			wins[m.Winner]++
+		}
+	}
+
+	standings := League{}
+
+	for _, p := range t.Players {
+		standings = append(standings, Player{p, wins[p]})
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Wins > standings[j].Wins
+	})
+
+	return standings
+}
+
+// Champion returns the winner of a finished tournament: the winner of the
+// final of a bracket, otherwise the top of the standings.
+func (t *Tournament) Champion() (string, bool) {
+	if !t.Finished() {
+		return "", false
+	}
+
+	if t.Format == SingleElimination {
+		return t.Matches[len(t.Matches)-1].Winner, true
+	}
+
+	return t.Standings()[0].Name, true
+}
+
+// MatchesInRound returns the matches of round, in the order they were drawn.
+func (t *Tournament) MatchesInRound(round int) []Match {
+	var matches []Match
+
+	for _, m := range t.Matches {
+		if m.Round == round {
+			matches = append(matches, m)
+		}
+	}
+
+	return matches
+}
+
+func (t *Tournament) addMatch(round int, a, b string) *Match {
+	t.Matches = append(t.Matches, Match{ID: len(t.Matches) + 1, Round: round, Players: [2]string{a, b}})
+	return &t.Matches[len(t.Matches)-1]
+}
+
+// advance draws whatever the result of m lets go ahead.
+func (t *Tournament) advance(m Match) {
+	switch t.Format {
+	case SingleElimination:
+		t.advanceBracket(m)
+	case Swiss:
+		if round := t.currentRound(); round < t.Rounds && t.roundDecided(round) {
+			t.drawSwissRound(round + 1)
+		}
+	}
+}
+
+func (t *Tournament) currentRound() int {
+	if len(t.Matches) == 0 {
+		return 0
+	}
+
+	return t.Matches[len(t.Matches)-1].Round
+}
+
+func (t *Tournament) roundDecided(round int) bool {
+	for _, m := range t.MatchesInRound(round) {
+		if !m.Decided() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// drawBracket lays out every round of a knockout bracket up front, with seeds
+// placed so the top two can only meet in the final, and plays out the byes.
+func (t *Tournament) drawBracket() {
+	size := 1
+
+	for size < len(t.Players) {
+		size *= 2
+		t.Rounds++
+	}
+
+	order := bracketOrder(size)
+
+	for i := 0; i < size; i += 2 {
+		t.addMatch(1, t.seedName(order[i]), t.seedName(order[i+1]))
+	}
+
+	for round, matches := 2, size/4; matches >= 1; round, matches = round+1, matches/2 {
+		for i := 0; i < matches; i++ {
+			t.addMatch(round, "", "")
+		}
+	}
+
+	for _, m := range t.MatchesInRound(1) {
+		if bye := byeWinner(m); bye != "" {
+			t.Matches[m.ID-1].Winner = bye
+			t.advanceBracket(t.Matches[m.ID-1])
+		}
+	}
+}
+
+// advanceBracket puts the winner of m into its place in the next round.
+func (t *Tournament) advanceBracket(m Match) {
+	if m.Round == t.Rounds {
+		return
+	}
+
+	first := t.MatchesInRound(m.Round)[0].ID
+	position := m.ID - first
+	next := t.MatchesInRound(m.Round + 1)[position/2].ID
+
+	t.Matches[next-1].Players[position%2] = m.Winner
+}
+
+// seedName returns the player with seed, counting from 1, or "" for a bye.
+func (t *Tournament) seedName(seed int) string {
+	if seed > len(t.Players) {
+		return ""
+	}
+
+	return t.Players[seed-1]
+}
+
+// drawRoundRobin schedules every round with the circle method, leaving out
+// the matches against a bye when there is an odd number of players.
+func (t *Tournament) drawRoundRobin() {
+	circle := append([]string{}, t.Players...)
+
+	if len(circle)%2 == 1 {
+		circle = append(circle, "")
+	}
+
+	t.Rounds = len(circle) - 1
+
+	for round := 1; round <= t.Rounds; round++ {
+		for i := 0; i < len(circle)/2; i++ {
+			a, b := circle[i], circle[len(circle)-1-i]
+
+			if a != "" && b != "" {
+				t.addMatch(round, a, b)
+			}
+		}
+
+		// Keep the first player fixed and rotate the rest one place.
+		last := circle[len(circle)-1]
+		copy(circle[2:], circle[1:len(circle)-1])
+		circle[1] = last
+	}
+}
+
+// drawSwissRound pairs players for round. The first round pairs the top half of
+// the seeds with the bottom half; later rounds pair players in order of the
+// standings with the next player they have not met. With an odd number of
+// players the lowest player without a bye yet gets one.
+func (t *Tournament) drawSwissRound(round int) {
+	order := append([]string{}, t.Players...)
+
+	if round > 1 {
+		order = order[:0]
+		for _, p := range t.Standings() {
+			order = append(order, p.Name)
+		}
+	}
+
+	if len(order)%2 == 1 {
+		bye := t.byeCandidate(order)
+		order = removePlayer(order, bye)
+		m := t.addMatch(round, bye, "")
+		m.Winner = bye
+	}
+
+	if round == 1 {
+		half := len(order) / 2
+		var folded []string
+		for i := 0; i < half; i++ {
+			folded = append(folded, order[i], order[i+half])
+		}
+		order = folded
+	}
+
+	played := t.opponents()
+
+	for len(order) > 0 {
+		player, rest := order[0], order[1:]
+		opponent := rest[0]
+
+		for _, candidate := range rest {
+			if !played[player][candidate] {
+				opponent = candidate
+				break
+			}
+		}
+
+		t.addMatch(round, player, opponent)
+		order = removePlayer(rest, opponent)
+	}
+}
+
+func (t *Tournament) byeCandidate(order []string) string {
+	hadBye := map[string]bool{}
+
+	for _, m := range t.Matches {
+		if bye := byeWinner(m); bye != "" {
+			hadBye[bye] = true
+		}
+	}
+
+	for i := len(order) - 1; i >= 0; i-- {
+		if !hadBye[order[i]] {
+			return order[i]
+		}
+	}
+
+	return order[len(order)-1]
+}
+
+func (t *Tournament) opponents() map[string]map[string]bool {
+	played := map[string]map[string]bool{}
+
+	for _, m := range t.Matches {
+		a, b := m.Players[0], m.Players[1]
+
+		if played[a] == nil {
+			played[a] = map[string]bool{}
+		}
+		if played[b] == nil {
+			played[b] = map[string]bool{}
+		}
+
+		played[a][b] = true
+		played[b][a] = true
+	}
+
+	return played
+}
+
+// seedPlayers orders players by their wins in league, keeping the given order for ties
+// and for players not in the league.
+func seedPlayers(league League, players []string) ([]string, error) {
+	seen := map[string]bool{}
+	var seeds []string
+
+	for _, p := range players {
+		if p == "" {
+			return nil, errors.New("a tournament player needs a name")
+		}
+
+		if seen[p] {
+			return nil, fmt.Errorf("%s is entered twice", p)
+		}
+
+		seen[p] = true
+		seeds = append(seeds, p)
+	}
+
+	if len(seeds) < 2 {
+		return nil, ErrTooFewEntrants
+	}
+
+	wins := func(name string) int {
+		if p := league.Find(name); p != nil {
+			return p.Wins
+		}
+		return -1
+	}
+
+	sort.SliceStable(seeds, func(i, j int) bool {
+		return wins(seeds[i]) > wins(seeds[j])
+	})
+
+	return seeds, nil
+}
+
+// bracketOrder lists the seeds of a bracket of size in the order they are
+// drawn, so that 1 plays size, and the higher seed of each pair can only meet
+// a stronger seed in a later round, e.g. 1 8 4 5 2 7 3 6.
+func bracketOrder(size int) []int {
+	order := []int{1}
+
+	for n := 2; n <= size; n *= 2 {
+		var next []int
+		for _, s := range order {
+			next = append(next, s, n+1-s)
+		}
+		order = next
+	}
+
+	return order
+}
+
+func swissRounds(players int) int {
+	rounds := 0
+
+	for n := 1; n < players; n *= 2 {
+		rounds++
+	}
+
+	return rounds
+}
+
+// byeWinner returns the player of a match against a bye, or "".
+func byeWinner(m Match) string {
+	switch {
+	case m.Players[1] == "":
+		return m.Players[0]
+	case m.Players[0] == "":
+		return m.Players[1]
+	default:
+		return ""
+	}
+}
+
+func removePlayer(players []string, player string) []string {
+	var kept []string
+
+	for _, p := range players {
+		if p != player {
+			kept = append(kept, p)
+		}
+	}
+
+	return kept
+}
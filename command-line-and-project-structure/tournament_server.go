@@ -0,0 +1,250 @@
+package poker
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const htmlContentType = "text/html; charset=utf-8"
+
+//go:embed tournament.html
+var tournamentHTML string
+
+var tournamentTemplate = template.Must(template.New("tournament").Parse(tournamentHTML))
+
+// newTournamentRequest is the body of a request to create a tournament.
+type newTournamentRequest struct {
+	Name    string           `json:"name"`
+	Format  TournamentFormat `json:"format"`
+	Players []string         `json:"players"`
+}
+
+// matchResultRequest is the body of a request reporting who won a match.
+type matchResultRequest struct {
+	Winner string `json:"winner"`
+}
+
+func (p *PlayerServer) tournamentsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		p.listTournaments(w)
+	case http.MethodPost:
+		p.createTournament(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// listTournaments writes every tournament, oldest first.
+func (p *PlayerServer) listTournaments(w http.ResponseWriter) {
+	tournaments, err := p.tournaments.List()
+
+	if err != nil {
+		tournamentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tournaments)
+}
+
+// createTournament draws a tournament seeded from the current league.
+func (p *PlayerServer) createTournament(w http.ResponseWriter, r *http.Request) {
+	var request newTournamentRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("problem parsing tournament, %v", err), http.StatusBadRequest)
+		return
+	}
+
+	league, err := p.store.GetLeague(r.Context())
+
+	if err != nil {
+		storeError(w, "could not get league to seed tournament", err)
+		return
+	}
+
+	tournament, err := p.tournaments.Create(request.Name, request.Format, league, request.Players)
+
+	if err != nil {
+		tournamentError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/tournaments/%d", tournament.ID))
+	writeJSON(w, http.StatusCreated, tournament)
+}
+
+// tournamentHandler serves /tournaments/{id} and /tournaments/{id}/matches/{match}.
+func (p *PlayerServer) tournamentHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/tournaments/"), "/")
+
+	id, err := strconv.Atoi(parts[0])
+
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		p.showTournament(w, r, id)
+	case len(parts) == 3 && parts[1] == "matches" && r.Method == http.MethodPost:
+		match, err := strconv.Atoi(parts[2])
+
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		p.recordMatchResult(w, r, id, match)
+	case len(parts) == 1 || (len(parts) == 3 && parts[1] == "matches"):
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// showTournament writes the tournament as HTML to browsers and as JSON to everyone else.
+func (p *PlayerServer) showTournament(w http.ResponseWriter, r *http.Request, id int) {
+	tournament, err := p.tournaments.Get(id)
+
+	if err != nil {
+		tournamentError(w, err)
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		writeJSON(w, http.StatusOK, tournament)
+		return
+	}
+
+	w.Header().Set("content-type", htmlContentType)
+
+	if err := tournamentTemplate.Execute(w, newTournamentView(tournament)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// recordMatchResult records the winner of a match, and a win for them in the league.
+// The result is saved before the win is recorded and undone if the win cannot be,
+// so a result is recorded exactly once and always with its win.
+func (p *PlayerServer) recordMatchResult(w http.ResponseWriter, r *http.Request, id, match int) {
+	var request matchResultRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("problem parsing match result, %v", err), http.StatusBadRequest)
+		return
+	}
+
+	var winErr error
+
+	tournament, err := p.tournaments.RecordResultWithWin(id, match, request.Winner, func() error {
+		winErr = p.store.RecordWin(r.Context(), request.Winner)
+		return winErr
+	})
+
+	if winErr != nil {
+		storeError(w, fmt.Sprintf("could not record win for %s", request.Winner), winErr)
+		return
+	}
+
+	if err != nil {
+		tournamentError(w, err)
+		return
+	}
+
+	p.winRecorded(r.Context(), request.Winner)
+	writeJSON(w, http.StatusOK, tournament)
+}
+
+// tournamentError answers with the status that fits a tournament error.
+func tournamentError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errSavingTournaments) {
+		storeError(w, "could not save tournament", err)
+		return
+	}
+
+	if errors.Is(err, errLoadingTournaments) || errors.Is(err, ErrWrongKey) || errors.Is(err, ErrNoKey) {
+		storeError(w, "could not load tournaments", err)
+		return
+	}
+
+	status := http.StatusBadRequest
+
+	switch {
+	case errors.Is(err, ErrUnknownTournament), errors.Is(err, ErrUnknownMatch):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrMatchDecided), errors.Is(err, ErrMatchNotReady):
+		status = http.StatusConflict
+	}
+
+	http.Error(w, err.Error(), status)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("content-type", jsonContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// tournamentView is a tournament laid out by round for the HTML template.
+type tournamentView struct {
+	Name      string
+	Format    TournamentFormat
+	Champion  string
+	Rounds    []roundView
+	Standings League
+}
+
+type roundView struct {
+	Number  int
+	Matches []matchView
+}
+
+type matchView struct {
+	ID      int
+	Players []matchPlayerView
+}
+
+// matchPlayerView is one side of a match. Without a name it is a bye in a decided
+// match, or a player still to come out of an earlier round.
+type matchPlayerView struct {
+	Name        string
+	Placeholder string
+	Winner      bool
+}
+
+func newTournamentView(t Tournament) tournamentView {
+	view := tournamentView{Name: t.Name, Format: t.Format, Standings: t.Standings()}
+	view.Champion, _ = t.Champion()
+
+	for round := 1; round <= t.Rounds; round++ {
+		rv := roundView{Number: round}
+
+		for _, m := range t.MatchesInRound(round) {
+			mv := matchView{ID: m.ID}
+
+			for _, name := range m.Players {
+				placeholder := "to be decided"
+				if m.Decided() {
+					placeholder = "bye"
+				}
+
+				mv.Players = append(mv.Players, matchPlayerView{name, placeholder, name != "" && name == m.Winner})
+			}
+
+			rv.Matches = append(rv.Matches, mv)
+		}
+
+		view.Rounds = append(view.Rounds, rv)
+	}
+
+	return view
+}
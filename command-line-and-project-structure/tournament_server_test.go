@@ -0,0 +1,223 @@
+package poker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTournamentEndpoints(t *testing.T) {
+	newServer := func(t *testing.T) (*PlayerServer, *StubPlayerStore) {
+		file, clean := createTempFile(t, "")
+		t.Cleanup(clean)
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		store := &StubPlayerStore{league: []Player{{"Chris", 33}, {"Cleo", 10}, {"Tiest", 5}, {"Apollo", 1}}}
+		return NewPlayerServer(store, WithTournaments(tournaments)), store
+	}
+
+	t.Run("creates a tournament seeded from the league", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		response := serve(server, newCreateTournamentRequest(`{"name":"October","format":"single-elimination"}`))
+
+		assertStatus(t, response.Code, http.StatusCreated)
+		assertContentType(t, response, jsonContentType)
+
+		if location := response.Header().Get("Location"); location != "/tournaments/1" {
+			t.Errorf("got location %q want %q", location, "/tournaments/1")
+		}
+
+		tournament := getTournamentFromResponse(t, response.Body)
+		assertMatchPlayers(t, tournament.MatchesInRound(1), [][2]string{{"Chris", "Apollo"}, {"Cleo", "Tiest"}})
+	})
+
+	t.Run("lists tournaments", func(t *testing.T) {
+		server, _ := newServer(t)
+		serve(server, newCreateTournamentRequest(`{"name":"October","format":"swiss"}`))
+
+		response := serve(server, httptest.NewRequest(http.MethodGet, "/tournaments", nil))
+
+		var got []Tournament
+		json.NewDecoder(response.Body).Decode(&got)
+
+		if len(got) != 1 || got[0].Name != "October" {
+			t.Errorf("unexpected tournaments %+v", got)
+		}
+	})
+
+	t.Run("records a match result as a win", func(t *testing.T) {
+		server, store := newServer(t)
+		serve(server, newCreateTournamentRequest(`{"name":"October","format":"single-elimination"}`))
+
+		response := serve(server, newMatchResultRequest(1, 2, `{"winner":"Tiest"}`))
+
+		assertStatus(t, response.Code, http.StatusOK)
+		AssertPlayerWin(t, store, "Tiest")
+
+		tournament := getTournamentFromResponse(t, response.Body)
+		assertMatchPlayers(t, tournament.MatchesInRound(2), [][2]string{{"", "Tiest"}})
+	})
+
+	t.Run("records a match result once however many times it is sent at once", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		t.Cleanup(clean)
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		store := &StubPlayerStore{league: []Player{{"Chris", 33}, {"Cleo", 10}, {"Tiest", 5}, {"Apollo", 1}}}
+		server := NewPlayerServer(slowWinStore{store}, WithTournaments(tournaments))
+		serve(server, newCreateTournamentRequest(`{"name":"October","format":"single-elimination"}`))
+
+		var wg sync.WaitGroup
+		statuses := make([]int, 20)
+
+		for i := range statuses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				statuses[i] = serve(server, newMatchResultRequest(1, 2, `{"winner":"Tiest"}`)).Code
+			}(i)
+		}
+
+		wg.Wait()
+
+		counts := map[int]int{}
+		for _, status := range statuses {
+			counts[status]++
+		}
+
+		if counts[http.StatusOK] != 1 || counts[http.StatusConflict] != len(statuses)-1 {
+			t.Errorf("got statuses %v want one %d and the rest %d", counts, http.StatusOK, http.StatusConflict)
+		}
+
+		AssertPlayerWin(t, store, "Tiest")
+	})
+
+	t.Run("does not keep a result whose win could not be recorded", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		t.Cleanup(clean)
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		store := winFailingStore{&StubPlayerStore{league: []Player{{"Chris", 33}, {"Cleo", 10}}}}
+		server := NewPlayerServer(store, WithTournaments(tournaments))
+		serve(server, newCreateTournamentRequest(`{"name":"October","format":"single-elimination"}`))
+
+		response := serve(server, newMatchResultRequest(1, 1, `{"winner":"Chris"}`))
+
+		assertStatus(t, response.Code, http.StatusServiceUnavailable)
+
+		tournament, err := tournaments.Get(1)
+		assertNoError(t, err)
+
+		if tournament.Matches[0].Decided() {
+			t.Error("expected the match to still be undecided")
+		}
+	})
+
+	cases := []struct {
+		desc    string
+		request *http.Request
+		status  int
+	}{
+		{"an unknown format", newCreateTournamentRequest(`{"name":"October","format":"ladder"}`), http.StatusBadRequest},
+		{"a body that is not JSON", newCreateTournamentRequest(`October`), http.StatusBadRequest},
+		{"an unknown tournament", httptest.NewRequest(http.MethodGet, "/tournaments/9", nil), http.StatusNotFound},
+		{"an unknown match", newMatchResultRequest(1, 9, `{"winner":"Tiest"}`), http.StatusNotFound},
+		{"a winner who is not playing", newMatchResultRequest(1, 2, `{"winner":"Chris"}`), http.StatusBadRequest},
+		{"a match still waiting for its players", newMatchResultRequest(1, 3, `{"winner":"Chris"}`), http.StatusConflict},
+	}
+
+	for _, c := range cases {
+		t.Run("rejects "+c.desc, func(t *testing.T) {
+			server, store := newServer(t)
+			serve(server, newCreateTournamentRequest(`{"name":"October","format":"single-elimination"}`))
+
+			response := serve(server, c.request)
+
+			assertStatus(t, response.Code, c.status)
+
+			if len(store.winCalls) != 0 {
+				t.Errorf("expected no wins to be recorded but got %v", store.winCalls)
+			}
+		})
+	}
+
+	t.Run("shows the bracket as HTML to browsers", func(t *testing.T) {
+		server, _ := newServer(t)
+		serve(server, newCreateTournamentRequest(`{"name":"October","format":"single-elimination"}`))
+		serve(server, newMatchResultRequest(1, 1, `{"winner":"Chris"}`))
+
+		request := httptest.NewRequest(http.MethodGet, "/tournaments/1", nil)
+		request.Header.Set("Accept", "text/html,application/xhtml+xml")
+		response := serve(server, request)
+
+		assertStatus(t, response.Code, http.StatusOK)
+		assertContentType(t, response, htmlContentType)
+
+		body := response.Body.String()
+
+		for _, want := range []string{"<h1>October</h1>", "<h2>Round 2</h2>", `<div class="winner">Chris</div>`, "<em>to be decided</em>"} {
+			if !strings.Contains(body, want) {
+				t.Errorf("expected the page to contain %q but got %s", want, body)
+			}
+		}
+	})
+}
+
+// slowWinStore is a StubPlayerStore that takes a while to record wins.
+type slowWinStore struct {
+	*StubPlayerStore
+}
+
+func (s slowWinStore) RecordWin(ctx context.Context, name string) error {
+	time.Sleep(10 * time.Millisecond)
+	return s.StubPlayerStore.RecordWin(ctx, name)
+}
+
+// winFailingStore is a StubPlayerStore that cannot record wins.
+type winFailingStore struct {
+	*StubPlayerStore
+}
+
+func (s winFailingStore) RecordWin(ctx context.Context, name string) error {
+	return fmt.Errorf("problem recording win, %w", ErrStoreUnavailable)
+}
+
+func serve(server http.Handler, request *http.Request) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	return response
+}
+
+func newCreateTournamentRequest(body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, "/tournaments", strings.NewReader(body))
+}
+
+func newMatchResultRequest(tournament, match int, body string) *http.Request {
+	return httptest.NewRequest(http.MethodPost, fmt.Sprintf("/tournaments/%d/matches/%d", tournament, match), strings.NewReader(body))
+}
+
+func getTournamentFromResponse(t testing.TB, body io.Reader) Tournament {
+	t.Helper()
+
+	var tournament Tournament
+
+	if err := json.NewDecoder(body).Decode(&tournament); err != nil {
+		t.Fatalf("unable to parse tournament from response, %v", err)
+	}
+
+	return tournament
+}
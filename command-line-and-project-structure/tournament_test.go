@@ -0,0 +1,272 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewTournament(t *testing.T) {
+	league := League{{"Chris", 33}, {"Cleo", 10}, {"Tiest", 5}}
+
+	t.Run("seeds players by their wins in the league", func(t *testing.T) {
+		tournament, err := NewTournament("October", RoundRobin, league, []string{"Tiest", "Apollo", "Chris"})
+
+		assertNoError(t, err)
+		assertPlayers(t, tournament.Players, []string{"Chris", "Tiest", "Apollo"})
+	})
+
+	t.Run("enters the whole league without players", func(t *testing.T) {
+		tournament, err := NewTournament("October", RoundRobin, league, nil)
+
+		assertNoError(t, err)
+		assertPlayers(t, tournament.Players, []string{"Chris", "Cleo", "Tiest"})
+	})
+
+	cases := []struct {
+		desc    string
+		format  TournamentFormat
+		players []string
+	}{
+		{"unknown formats", "ladder", []string{"Chris", "Cleo"}},
+		{"a single player", Swiss, []string{"Chris"}},
+		{"players entered twice", Swiss, []string{"Chris", "Cleo", "Chris"}},
+		{"players without a name", Swiss, []string{"Chris", ""}},
+	}
+
+	for _, c := range cases {
+		t.Run("refuses "+c.desc, func(t *testing.T) {
+			if _, err := NewTournament("October", c.format, league, c.players); err == nil {
+				t.Error("expected an error but didn't get one")
+			}
+		})
+	}
+}
+
+func TestSingleElimination(t *testing.T) {
+	t.Run("draws the seeds so the top two can only meet in the final", func(t *testing.T) {
+		tournament := newTestTournament(t, SingleElimination, 8)
+
+		assertMatchPlayers(t, tournament.MatchesInRound(1), [][2]string{
+			{"p1", "p8"}, {"p4", "p5"}, {"p2", "p7"}, {"p3", "p6"},
+		})
+
+		if tournament.Rounds != 3 || len(tournament.Matches) != 7 {
+			t.Errorf("got %d rounds of %d matches want 3 rounds of 7", tournament.Rounds, len(tournament.Matches))
+		}
+	})
+
+	t.Run("gives the top seeds byes into the next round", func(t *testing.T) {
+		tournament := newTestTournament(t, SingleElimination, 5)
+
+		assertMatchPlayers(t, tournament.MatchesInRound(2), [][2]string{
+			{"p1", ""}, {"p2", "p3"},
+		})
+	})
+
+	t.Run("sends winners through to the final", func(t *testing.T) {
+		tournament := newTestTournament(t, SingleElimination, 4)
+
+		recordResult(t, tournament, 1, "p1")
+		recordResult(t, tournament, 2, "p3")
+
+		assertMatchPlayers(t, tournament.MatchesInRound(2), [][2]string{{"p1", "p3"}})
+
+		if _, finished := tournament.Champion(); finished {
+			t.Error("didn't expect a champion before the final")
+		}
+
+		recordResult(t, tournament, 3, "p3")
+
+		assertChampion(t, tournament, "p3")
+	})
+
+	t.Run("refuses results that do not fit the bracket", func(t *testing.T) {
+		tournament := newTestTournament(t, SingleElimination, 4)
+		recordResult(t, tournament, 1, "p1")
+
+		cases := []struct {
+			match  int
+			winner string
+			err    error
+		}{
+			{0, "p1", ErrUnknownMatch},
+			{4, "p1", ErrUnknownMatch},
+			{1, "p4", ErrMatchDecided},
+			{2, "p1", ErrNotInMatch},
+			{3, "p1", ErrMatchNotReady},
+		}
+
+		for _, c := range cases {
+			if err := tournament.RecordResult(c.match, c.winner); !errors.Is(err, c.err) {
+				t.Errorf("recording %s winning match %d got %v want %v", c.winner, c.match, err, c.err)
+			}
+		}
+	})
+}
+
+func TestRoundRobin(t *testing.T) {
+	tournament := newTestTournament(t, RoundRobin, 4)
+
+	for _, m := range tournament.Matches {
+		recordResult(t, tournament, m.ID, higherSeed(tournament, m))
+	}
+
+	assertChampion(t, tournament, "p1")
+	assertLeague(t, tournament.Standings(), []Player{{"p1", 3}, {"p2", 2}, {"p3", 1}, {"p4", 0}})
+}
+
+func TestSwiss(t *testing.T) {
+	t.Run("pairs the top half of the seeds with the bottom half first", func(t *testing.T) {
+		tournament := newTestTournament(t, Swiss, 4)
+
+		assertMatchPlayers(t, tournament.Matches, [][2]string{{"p1", "p3"}, {"p2", "p4"}})
+	})
+
+	t.Run("pairs winners with winners in the next round", func(t *testing.T) {
+		tournament := newTestTournament(t, Swiss, 4)
+
+		recordResult(t, tournament, 1, "p3")
+		recordResult(t, tournament, 2, "p2")
+
+		assertMatchPlayers(t, tournament.MatchesInRound(2), [][2]string{{"p2", "p3"}, {"p1", "p4"}})
+	})
+
+	t.Run("gives the lowest player a bye with an odd number of players", func(t *testing.T) {
+		tournament := newTestTournament(t, Swiss, 3)
+
+		assertMatchPlayers(t, tournament.MatchesInRound(1), [][2]string{{"p3", ""}, {"p1", "p2"}})
+	})
+}
+
+// TestPropertiesOfTournaments plays out every format for many sizes of field,
+// always letting the higher seed win, and checks what must hold for all of them.
+func TestPropertiesOfTournaments(t *testing.T) {
+	for _, format := range []TournamentFormat{SingleElimination, RoundRobin, Swiss} {
+		for players := 2; players <= 17; players++ {
+			t.Run(fmt.Sprintf("%s with %d players", format, players), func(t *testing.T) {
+				tournament := newTestTournament(t, format, players)
+
+				for !tournament.Finished() {
+					played := false
+
+					for _, m := range tournament.Matches {
+						if !m.Decided() && m.Players[0] != "" && m.Players[1] != "" {
+							recordResult(t, tournament, m.ID, higherSeed(tournament, m))
+							played = true
+						}
+					}
+
+					if !played {
+						t.Fatalf("tournament is stuck with matches %+v", tournament.Matches)
+					}
+				}
+
+				assertChampion(t, tournament, "p1")
+				assertNoOneDoubleBooked(t, tournament)
+
+				if format == RoundRobin && len(tournament.Matches) != players*(players-1)/2 {
+					t.Errorf("got %d matches want every pair to meet once", len(tournament.Matches))
+				}
+
+				if format != Swiss {
+					assertNoRematches(t, tournament)
+				}
+			})
+		}
+	}
+}
+
+func newTestTournament(t testing.TB, format TournamentFormat, players int) *Tournament {
+	t.Helper()
+
+	var names []string
+	for i := 1; i <= players; i++ {
+		names = append(names, fmt.Sprintf("p%d", i))
+	}
+
+	tournament, err := NewTournament("Test", format, nil, names)
+	assertNoError(t, err)
+
+	return tournament
+}
+
+func higherSeed(tournament *Tournament, m Match) string {
+	for _, p := range tournament.Players {
+		if p == m.Players[0] || p == m.Players[1] {
+			return p
+		}
+	}
+	return ""
+}
+
+func recordResult(t testing.TB, tournament *Tournament, match int, winner string) {
+	t.Helper()
+	assertNoError(t, tournament.RecordResult(match, winner))
+}
+
+func assertChampion(t testing.TB, tournament *Tournament, want string) {
+	t.Helper()
+
+	got, finished := tournament.Champion()
+
+	if !finished || got != want {
+		t.Errorf("got champion %q (finished %v) want %q", got, finished, want)
+	}
+}
+
+func assertMatchPlayers(t testing.TB, matches []Match, want [][2]string) {
+	t.Helper()
+
+	var got [][2]string
+	for _, m := range matches {
+		got = append(got, m.Players)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got matches %v want %v", got, want)
+	}
+}
+
+func assertPlayers(t testing.TB, got, want []string) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got players %v want %v", got, want)
+	}
+}
+
+func assertNoOneDoubleBooked(t testing.TB, tournament *Tournament) {
+	t.Helper()
+
+	for round := 1; round <= tournament.Rounds; round++ {
+		seen := map[string]bool{}
+
+		for _, m := range tournament.MatchesInRound(round) {
+			for _, p := range m.Players {
+				if p != "" && seen[p] {
+					t.Errorf("%s plays twice in round %d", p, round)
+				}
+				seen[p] = true
+			}
+		}
+	}
+}
+
+func assertNoRematches(t testing.TB, tournament *Tournament) {
+	t.Helper()
+
+	seen := map[[2]string]bool{}
+
+	for _, m := range tournament.Matches {
+		pair := m.Players
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+
+		if pair[0] != "" && seen[pair] {
+			t.Errorf("%s and %s meet twice", pair[0], pair[1])
+		}
+		seen[pair] = true
+	}
+}
@@ -0,0 +1,389 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// ErrUnknownTournament is returned for a tournament id that was never created.
+var ErrUnknownTournament = errors.New("no such tournament")
+
+var (
+	// errSavingTournaments is wrapped by failures to write the tournaments file.
+	errSavingTournaments = errors.New("problem saving tournaments")
+
+	// errLoadingTournaments is wrapped by failures to read the tournaments file.
+	errLoadingTournaments = errors.New("problem loading tournaments")
+)
+
+// Tournaments keeps every tournament in a JSON file, encrypted if it has a key.
+// Like FileSystemPlayerStore it holds a sibling lock file while it reads or writes the file,
+// and reloads the tournaments whenever another process has changed them.
+type Tournaments struct {
+	mu          sync.Mutex
+	tape        *tape
+	lock        *os.File
+	seen        fileVersion
+	tournaments []*Tournament
+	encryptor   *encryptor
+}
+
+// TournamentsOption configures Tournaments.
+type TournamentsOption func(*Tournaments) error
+
+// WithTournamentsEncryptionKey encrypts the tournaments file with key, encrypting a plain file
+// on open. A nil key leaves the file unencrypted.
+func WithTournamentsEncryptionKey(key []byte) TournamentsOption {
+	return func(t *Tournaments) error {
+		encryptor, err := encryptorFor(key)
+		t.encryptor = encryptor
+		return err
+	}
+}
+
+// TournamentsFromFile opens the tournaments kept at path, creating the file if needed.
+func TournamentsFromFile(path string, options ...TournamentsOption) (*Tournaments, func(), error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %v, %s", err, path)
+	}
+
+	tournaments, err := NewTournaments(file, options...)
+
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	closeFunc := func() {
+		tournaments.Close()
+		file.Close()
+	}
+
+	return tournaments, closeFunc, nil
+}
+
+// NewTournaments loads the tournaments kept in file.
+func NewTournaments(file *os.File, options ...TournamentsOption) (*Tournaments, error) {
+	lock, err := os.OpenFile(file.Name()+".lock", os.O_RDWR|os.O_CREATE, 0666)
+
+	if err != nil {
+		return nil, fmt.Errorf("problem opening lock file for %s, %v", file.Name(), err)
+	}
+
+	t := &Tournaments{tape: newTape(file), lock: lock}
+
+	for _, option := range options {
+		if err := option(t); err != nil {
+			t.Close()
+			return nil, err
+		}
+	}
+
+	err = t.withLock(lockExclusive, func() error {
+		if _, err := t.tape.refresh(); err != nil {
+			return err
+		}
+
+		if err := initialiseDBFile(t.tape.file, "[]"); err != nil {
+			return fmt.Errorf("problem initialising tournaments file, %v", err)
+		}
+
+		encrypted, err := t.load()
+
+		if err != nil {
+			return err
+		}
+
+		if encrypted != (t.encryptor != nil) {
+			return t.save()
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Close releases the files the tournaments opened themselves.
+func (t *Tournaments) Close() error {
+	t.tape.Close()
+	return t.lock.Close()
+}
+
+// Rekey re-encrypts the tournaments with key, or stores them unencrypted if key is nil.
+func (t *Tournaments) Rekey(key []byte) error {
+	encryptor, err := encryptorFor(key)
+
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.update(func() error {
+		t.encryptor = encryptor
+		return nil
+	})
+}
+
+// Create draws a new tournament and keeps it.
+func (t *Tournaments) Create(name string, format TournamentFormat, league League, players []string) (Tournament, error) {
+	tournament, err := NewTournament(name, format, league, players)
+
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	err = t.update(func() error {
+		tournament.ID = len(t.tournaments) + 1
+		t.tournaments = append(t.tournaments, tournament)
+		return nil
+	})
+
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return tournament.clone(), nil
+}
+
+// Get returns the tournament with id.
+func (t *Tournaments) Get(id int) (Tournament, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var tournament Tournament
+
+	err := t.read(func() error {
+		if id < 1 || id > len(t.tournaments) {
+			return ErrUnknownTournament
+		}
+
+		tournament = t.tournaments[id-1].clone()
+		return nil
+	})
+
+	return tournament, err
+}
+
+// List returns every tournament, oldest first.
+func (t *Tournaments) List() ([]Tournament, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := []Tournament{}
+
+	err := t.read(func() error {
+		for _, tournament := range t.tournaments {
+			list = append(list, tournament.clone())
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// RecordResult records winner as the winner of match in the tournament with id.
+func (t *Tournaments) RecordResult(id, match int, winner string) (Tournament, error) {
+	return t.RecordResultWithWin(id, match, winner, func() error { return nil })
+}
+
+// RecordResultWithWin records winner as the winner of match like RecordResult and calls recordWin
+// once the result is saved, before any other result can be recorded, even by another process.
+// If recordWin fails the result is undone and its error returned, so a result is only ever kept
+// along with its win.
+func (t *Tournaments) RecordResultWithWin(id, match int, winner string, recordWin func() error) (Tournament, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var updated Tournament
+
+	err := t.withLock(lockExclusive, func() error {
+		if err := t.reloadIfChanged(); err != nil {
+			return err
+		}
+
+		if id < 1 || id > len(t.tournaments) {
+			return ErrUnknownTournament
+		}
+
+		previous := t.tournaments[id-1]
+		updated = previous.clone()
+
+		if err := updated.RecordResult(match, winner); err != nil {
+			return err
+		}
+
+		t.tournaments[id-1] = &updated
+
+		if err := t.save(); err != nil {
+			t.tournaments[id-1] = previous
+			return err
+		}
+
+		if err := recordWin(); err != nil {
+			t.tournaments[id-1] = previous
+
+			if saveErr := t.save(); saveErr != nil {
+				log.Printf("could not undo result of match %d in tournament %d after failing to record the win, %v", match, id, saveErr)
+			}
+
+			return err
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return Tournament{}, err
+	}
+
+	return updated.clone(), nil
+}
+
+// read calls fn with the latest tournaments on disk while holding a shared lock.
+func (t *Tournaments) read(fn func() error) error {
+	return t.withLock(lockShared, func() error {
+		if err := t.reloadIfChanged(); err != nil {
+			return err
+		}
+
+		return fn()
+	})
+}
+
+// update applies change to the latest tournaments on disk and saves them while holding an
+// exclusive lock. Nothing is saved if change fails, and the tournaments on disk are reloaded
+// if they cannot be saved.
+func (t *Tournaments) update(change func() error) error {
+	return t.withLock(lockExclusive, func() error {
+		if err := t.reloadIfChanged(); err != nil {
+			return err
+		}
+
+		if err := change(); err != nil {
+			return err
+		}
+
+		if err := t.save(); err != nil {
+			t.load()
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (t *Tournaments) withLock(how lockType, fn func() error) error {
+	if err := lockFile(t.lock, how); err != nil {
+		return fmt.Errorf("%w, could not lock %s, %v", errLoadingTournaments, t.lock.Name(), err)
+	}
+
+	defer unlockFile(t.lock)
+
+	return fn()
+}
+
+// reloadIfChanged reloads the tournaments if another process has written them since they were last seen.
+func (t *Tournaments) reloadIfChanged() error {
+	replaced, err := t.tape.refresh()
+
+	if err != nil {
+		return fmt.Errorf("%w, %v", errLoadingTournaments, err)
+	}
+
+	version, err := versionOf(t.tape.file.Name())
+
+	if err != nil {
+		return fmt.Errorf("%w, %v", errLoadingTournaments, err)
+	}
+
+	if !replaced && version == t.seen {
+		return nil
+	}
+
+	_, err = t.load()
+	return err
+}
+
+// load reads the tournaments from the file, reporting whether it was encrypted.
+func (t *Tournaments) load() (bool, error) {
+	t.tape.file.Seek(0, 0)
+
+	data, err := io.ReadAll(t.tape.file)
+
+	if err != nil {
+		return false, fmt.Errorf("%w from file %s, %v", errLoadingTournaments, t.tape.file.Name(), err)
+	}
+
+	encrypted := isEncrypted(data)
+
+	if data, err = decryptDatabase(data, t.encryptor); err != nil {
+		return false, fmt.Errorf("problem decrypting tournaments file %s, %w", t.tape.file.Name(), err)
+	}
+
+	var tournaments []*Tournament
+
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&tournaments); err != nil {
+		return false, fmt.Errorf("%w from file %s, %v", errLoadingTournaments, t.tape.file.Name(), err)
+	}
+
+	t.tournaments = tournaments
+	t.seen, err = versionOf(t.tape.file.Name())
+
+	return encrypted, err
+}
+
+func (t *Tournaments) save() error {
+	data, _ := json.Marshal(t.tournaments)
+
+	if t.encryptor != nil {
+		var err error
+
+		if data, err = t.encryptor.encrypt(data); err != nil {
+			return fmt.Errorf("%w, %v", errSavingTournaments, err)
+		}
+	}
+
+	if _, err := t.tape.Write(data); err != nil {
+		return fmt.Errorf("%w, %v", errSavingTournaments, err)
+	}
+
+	version, err := versionOf(t.tape.file.Name())
+
+	if err != nil {
+		return fmt.Errorf("%w, %v", errSavingTournaments, err)
+	}
+
+	t.seen = version
+	return nil
+}
+
+func (t *Tournament) clone() Tournament {
+	c := *t
+	c.Players = append([]string{}, t.Players...)
+	c.Matches = append([]Match{}, t.Matches...)
+	return c
+}
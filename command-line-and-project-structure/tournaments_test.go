@@ -0,0 +1,180 @@
+package poker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestTournaments(t *testing.T) {
+	t.Run("keeps tournaments and their results across restarts", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		created, err := tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"})
+		assertNoError(t, err)
+
+		if created.ID != 1 {
+			t.Errorf("got id %d want %d", created.ID, 1)
+		}
+
+		_, err = tournaments.RecordResult(created.ID, 1, "Cleo")
+		assertNoError(t, err)
+
+		tournaments, err = NewTournaments(file)
+		assertNoError(t, err)
+
+		got, err := tournaments.Get(created.ID)
+		assertNoError(t, err)
+
+		if champion, _ := got.Champion(); champion != "Cleo" {
+			t.Errorf("got champion %q want %q", champion, "Cleo")
+		}
+
+		list, err := tournaments.List()
+		assertNoError(t, err)
+
+		if len(list) != 1 {
+			t.Errorf("got %d tournaments want %d", len(list), 1)
+		}
+	})
+
+	t.Run("shares the file with another process", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		other, close, err := TournamentsFromFile(file.Name())
+		assertNoError(t, err)
+		defer close()
+
+		created, err := tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"})
+		assertNoError(t, err)
+
+		_, err = other.RecordResult(created.ID, 1, "Cleo")
+		assertNoError(t, err)
+
+		if _, err := tournaments.RecordResult(created.ID, 1, "Chris"); !errors.Is(err, ErrMatchDecided) {
+			t.Errorf("got %v want %v", err, ErrMatchDecided)
+		}
+
+		got, err := tournaments.Get(created.ID)
+		assertNoError(t, err)
+
+		if champion, _ := got.Champion(); champion != "Cleo" {
+			t.Errorf("got champion %q want %q", champion, "Cleo")
+		}
+	})
+
+	t.Run("gives tournaments created by several processes their own ids", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		all := make([]*Tournaments, 4)
+
+		for i := range all {
+			tournaments, close, err := TournamentsFromFile(file.Name())
+			assertNoError(t, err)
+			defer close()
+			all[i] = tournaments
+		}
+
+		var wg sync.WaitGroup
+
+		for _, tournaments := range all {
+			wg.Add(1)
+			go func(tournaments *Tournaments) {
+				defer wg.Done()
+				if _, err := tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"}); err != nil {
+					t.Error(err)
+				}
+			}(tournaments)
+		}
+
+		wg.Wait()
+
+		list, err := all[0].List()
+		assertNoError(t, err)
+
+		for i, tournament := range list {
+			if tournament.ID != i+1 {
+				t.Errorf("got id %d for tournament %d want %d", tournament.ID, i+1, i+1)
+			}
+		}
+
+		if len(list) != len(all) {
+			t.Errorf("got %d tournaments want %d", len(list), len(all))
+		}
+	})
+
+	t.Run("reports unknown tournaments", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		if _, err := tournaments.Get(1); !errors.Is(err, ErrUnknownTournament) {
+			t.Errorf("got %v want %v", err, ErrUnknownTournament)
+		}
+
+		if _, err := tournaments.RecordResult(1, 1, "Cleo"); !errors.Is(err, ErrUnknownTournament) {
+			t.Errorf("got %v want %v", err, ErrUnknownTournament)
+		}
+	})
+
+	t.Run("does not change a tournament it cannot save", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		created, err := tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"})
+		assertNoError(t, err)
+
+		tournaments.tape.createTemp = (&failingTempFile{writeErr: errors.New("disk full")}).create
+
+		if _, err := tournaments.RecordResult(created.ID, 1, "Cleo"); !errors.Is(err, errSavingTournaments) {
+			t.Errorf("got %v want %v", err, errSavingTournaments)
+		}
+
+		got, _ := tournaments.Get(created.ID)
+
+		if got.Matches[0].Decided() {
+			t.Error("expected the match to still be undecided")
+		}
+	})
+
+	t.Run("undoes a result whose win could not be recorded", func(t *testing.T) {
+		file, clean := createTempFile(t, "")
+		defer clean()
+
+		tournaments, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		created, err := tournaments.Create("October", SingleElimination, nil, []string{"Chris", "Cleo"})
+		assertNoError(t, err)
+
+		winErr := errors.New("store down")
+		_, err = tournaments.RecordResultWithWin(created.ID, 1, "Cleo", func() error { return winErr })
+
+		if err != winErr {
+			t.Errorf("got %v want %v", err, winErr)
+		}
+
+		reopened, err := NewTournaments(file)
+		assertNoError(t, err)
+
+		for _, tournaments := range []*Tournaments{tournaments, reopened} {
+			if got, _ := tournaments.Get(created.ID); got.Matches[0].Decided() {
+				t.Error("expected the match to still be undecided")
+			}
+		}
+	})
+}
@@ -0,0 +1,335 @@
+package poker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Types of event sent to webhooks.
+const (
+	WebhookEventWin           = "win"
+	WebhookEventLeaderChanged = "leader-changed"
+)
+
+// Headers sent with every webhook delivery.
+const (
+	WebhookSignatureHeader = "X-Poker-Signature"
+	WebhookEventHeader     = "X-Poker-Event"
+	WebhookDeliveryHeader  = "X-Poker-Delivery"
+)
+
+const (
+	defaultWebhookRetries = 3
+	defaultWebhookBackoff = time.Second
+	webhookQueueSize      = 100
+)
+
+// WebhookEvent is the JSON payload sent to webhooks. Player won, or for
+// leader-changed took the lead from Previous.
+type WebhookEvent struct {
+	ID       string    `json:"id"`
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	Player   string    `json:"player"`
+	Previous string    `json:"previous,omitempty"`
+}
+
+// DeadLetter is an event that could not be delivered to a webhook, kept as a JSON line in the dead-letter file.
+type DeadLetter struct {
+	Time     time.Time    `json:"time"`
+	URL      string       `json:"url"`
+	Event    WebhookEvent `json:"event"`
+	Attempts int          `json:"attempts"`
+	Error    string       `json:"error"`
+}
+
+// SignWebhook returns the signature header value for body: the hex HMAC-SHA256 of
+// body keyed with secret, prefixed with "sha256=".
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature reports whether signature is the signature of body with secret,
+// so receivers can check a delivery came from the server.
+func VerifyWebhookSignature(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, body)), []byte(signature))
+}
+
+// ErrNoWebhookSecret is returned when webhooks are asked for without a secret to sign them with.
+var ErrNoWebhookSecret = errors.New("webhooks need a secret to sign events with")
+
+// Webhooks delivers events to a set of URLs in the background. Each URL has its own
+// queue, so a slow receiver does not hold up the others. Failed deliveries are retried
+// with exponential backoff and then written to the dead-letter file, if there is one.
+type Webhooks struct {
+	secret      []byte
+	closing     context.Context
+	stop        context.CancelFunc
+	client      *http.Client
+	retries     int
+	backoff     time.Duration
+	deadLetters *os.File
+	deadMu      sync.Mutex
+	endpoints   []*webhookEndpoint
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
+	closed      bool
+}
+
+type webhookEndpoint struct {
+	url   string
+	queue chan WebhookEvent
+}
+
+// WebhookOption configures Webhooks.
+type WebhookOption func(*Webhooks)
+
+// WithWebhookRetries retries each failed delivery up to retries times, waiting
+// backoff before the first retry and doubling the wait before each one after.
+func WithWebhookRetries(retries int, backoff time.Duration) WebhookOption {
+	return func(w *Webhooks) {
+		w.retries = retries
+		w.backoff = backoff
+	}
+}
+
+// WithWebhookHTTPClient delivers events with client instead of a default client.
+func WithWebhookHTTPClient(client *http.Client) WebhookOption {
+	return func(w *Webhooks) {
+		w.client = client
+	}
+}
+
+// WithDeadLetterFile appends events that could not be delivered to file.
+func WithDeadLetterFile(file *os.File) WebhookOption {
+	return func(w *Webhooks) {
+		w.deadLetters = file
+	}
+}
+
+// NewWebhooks starts delivering events to urls, signed with secret, which must not be empty
+// if there are any urls.
+func NewWebhooks(urls []string, secret []byte, options ...WebhookOption) (*Webhooks, error) {
+	if len(urls) > 0 && len(secret) == 0 {
+		return nil, ErrNoWebhookSecret
+	}
+
+	w := &Webhooks{
+		secret:  secret,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		retries: defaultWebhookRetries,
+		backoff: defaultWebhookBackoff,
+	}
+
+	w.closing, w.stop = context.WithCancel(context.Background())
+
+	for _, option := range options {
+		option(w)
+	}
+
+	for _, url := range urls {
+		endpoint := &webhookEndpoint{url: url, queue: make(chan WebhookEvent, webhookQueueSize)}
+		w.endpoints = append(w.endpoints, endpoint)
+
+		w.wg.Add(1)
+		go w.deliverAll(endpoint)
+	}
+
+	return w, nil
+}
+
+// Send queues event for every URL without waiting for it to be delivered. An event
+// that does not fit in a full queue, or is sent after Close, goes straight to the dead-letter file.
+func (w *Webhooks) Send(event WebhookEvent) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if event.ID == "" {
+		event.ID = NewRequestID()
+	}
+
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	for _, endpoint := range w.endpoints {
+		if w.closed {
+			w.deadLetter(endpoint.url, event, 0, fmt.Errorf("webhooks are closed"))
+			continue
+		}
+
+		select {
+		case endpoint.queue <- event:
+		default:
+			w.deadLetter(endpoint.url, event, 0, fmt.Errorf("delivery queue is full"))
+		}
+	}
+}
+
+// Close stops accepting events and waits for those already queued to be delivered or given up on.
+// Events still queued get one attempt each, without waiting to retry; those that fail, and any
+// waiting to be retried, go to the dead-letter file.
+func (w *Webhooks) Close() {
+	w.mu.Lock()
+
+	if !w.closed {
+		w.closed = true
+		w.stop()
+
+		for _, endpoint := range w.endpoints {
+			close(endpoint.queue)
+		}
+	}
+
+	w.mu.Unlock()
+	w.wg.Wait()
+}
+
+func (w *Webhooks) deliverAll(endpoint *webhookEndpoint) {
+	defer w.wg.Done()
+
+	for event := range endpoint.queue {
+		w.deliver(endpoint.url, event)
+	}
+}
+
+// deliver posts event to url, retrying until it is accepted or the retries run out.
+func (w *Webhooks) deliver(url string, event WebhookEvent) {
+	body, _ := json.Marshal(event)
+	wait := w.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := w.post(url, event, body)
+
+		if err == nil {
+			return
+		}
+
+		if attempt > w.retries {
+			w.deadLetter(url, event, attempt, err)
+			return
+		}
+
+		if !w.sleep(wait) {
+			w.deadLetter(url, event, attempt, fmt.Errorf("%v, webhooks closed before it could be retried", err))
+			return
+		}
+
+		wait *= 2
+	}
+}
+
+// sleep waits for d, reporting false if the webhooks are closed first.
+func (w *Webhooks) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-w.closing.Done():
+		return false
+	}
+}
+
+func (w *Webhooks) post(url string, event WebhookEvent, body []byte) error {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+
+	if err != nil {
+		return fmt.Errorf("problem creating webhook request, %v", err)
+	}
+
+	request.Header.Set("content-type", jsonContentType)
+	request.Header.Set(WebhookSignatureHeader, SignWebhook(w.secret, body))
+	request.Header.Set(WebhookEventHeader, event.Type)
+	request.Header.Set(WebhookDeliveryHeader, event.ID)
+
+	response, err := w.client.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 4096))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %d %s", response.StatusCode, http.StatusText(response.StatusCode))
+	}
+
+	return nil
+}
+
+func (w *Webhooks) deadLetter(url string, event WebhookEvent, attempts int, err error) {
+	log.Printf("could not deliver %s event %s to %s, %v", event.Type, event.ID, url, err)
+
+	if w.deadLetters == nil {
+		return
+	}
+
+	line, _ := json.Marshal(DeadLetter{
+		Time:     time.Now(),
+		URL:      url,
+		Event:    event,
+		Attempts: attempts,
+		Error:    err.Error(),
+	})
+
+	w.deadMu.Lock()
+	defer w.deadMu.Unlock()
+
+	if _, err := w.deadLetters.Write(append(line, '\n')); err != nil {
+		log.Printf("could not write dead letter to %s, %v", w.deadLetters.Name(), err)
+	}
+}
+
+// leaderTracker remembers who leads the league, to tell when the lead changes hands.
+type leaderTracker struct {
+	mu     sync.Mutex
+	known  bool
+	leader string
+}
+
+// observe records the leader of league and returns the previous leader if the lead
+// changed. A tie for first place leaves the lead where it was.
+func (l *leaderTracker) observe(league League) (string, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	top := ""
+	if len(league) > 0 {
+		top = league[0].Name
+	}
+
+	if !l.known {
+		l.known, l.leader = true, top
+		return "", false
+	}
+
+	if top == "" || top == l.leader {
+		return "", false
+	}
+
+	if previous := league.Find(l.leader); previous != nil && previous.Wins >= league[0].Wins {
+		return "", false
+	}
+
+	previous := l.leader
+	l.leader = top
+
+	return previous, true
+}
@@ -0,0 +1,253 @@
+package poker
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var webhookSecret = []byte("s3cret")
+
+// webhookReceiver records the events posted to it, failing the first failures deliveries.
+type webhookReceiver struct {
+	t        testing.TB
+	mu       sync.Mutex
+	failures int
+	attempts int
+	events   []WebhookEvent
+}
+
+func newWebhookReceiver(t testing.TB, failures int) (*webhookReceiver, *httptest.Server) {
+	t.Helper()
+	receiver := &webhookReceiver{t: t, failures: failures}
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+// newWebhooks starts Webhooks delivering to urls, signed with webhookSecret.
+func newWebhooks(t testing.TB, urls []string, options ...WebhookOption) *Webhooks {
+	t.Helper()
+	webhooks, err := NewWebhooks(urls, webhookSecret, options...)
+	assertNoError(t, err)
+	return webhooks
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	if !VerifyWebhookSignature(webhookSecret, body, req.Header.Get(WebhookSignatureHeader)) {
+		r.t.Errorf("signature %q does not match body %s", req.Header.Get(WebhookSignatureHeader), body)
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		r.t.Errorf("could not parse webhook body %s, %v", body, err)
+	}
+
+	if got := req.Header.Get(WebhookEventHeader); got != event.Type {
+		r.t.Errorf("got event header %q want %q", got, event.Type)
+	}
+
+	if got := req.Header.Get(WebhookDeliveryHeader); got != event.ID {
+		r.t.Errorf("got delivery header %q want %q", got, event.ID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts++
+	if r.attempts <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	r.events = append(r.events, event)
+}
+
+func (r *webhookReceiver) received() []WebhookEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]WebhookEvent(nil), r.events...)
+}
+
+func TestWebhooks(t *testing.T) {
+	t.Run("posts signed events", func(t *testing.T) {
+		receiver, server := newWebhookReceiver(t, 0)
+		webhooks := newWebhooks(t, []string{server.URL})
+
+		webhooks.Send(WebhookEvent{Type: WebhookEventWin, Player: "Pepper"})
+		webhooks.Close()
+
+		events := receiver.received()
+
+		if len(events) != 1 {
+			t.Fatalf("got %d events want 1", len(events))
+		}
+
+		if events[0].Player != "Pepper" || events[0].ID == "" || events[0].Time.IsZero() {
+			t.Errorf("got event %+v", events[0])
+		}
+	})
+
+	t.Run("retries failed deliveries", func(t *testing.T) {
+		receiver, server := newWebhookReceiver(t, 2)
+		webhooks := newWebhooks(t, []string{server.URL}, WithWebhookRetries(2, time.Millisecond))
+		defer webhooks.Close()
+
+		webhooks.Send(WebhookEvent{Type: WebhookEventWin, Player: "Pepper"})
+		waitFor(t, func() bool { return len(receiver.received()) > 0 })
+
+		if got := len(receiver.received()); got != 1 {
+			t.Errorf("got %d events want 1", got)
+		}
+	})
+
+	t.Run("dead-letters events once the retries run out", func(t *testing.T) {
+		receiver, server := newWebhookReceiver(t, 3)
+		deadLetters, clean := createTempFile(t, "")
+		defer clean()
+
+		webhooks := newWebhooks(t, []string{server.URL},
+			WithWebhookRetries(2, time.Millisecond), WithDeadLetterFile(deadLetters))
+		defer webhooks.Close()
+
+		webhooks.Send(WebhookEvent{Type: WebhookEventWin, Player: "Pepper"})
+		waitFor(t, func() bool { return len(readDeadLetters(t, deadLetters.Name())) > 0 })
+
+		if got := len(receiver.received()); got != 0 {
+			t.Errorf("got %d events want 0", got)
+		}
+
+		letters := readDeadLetters(t, deadLetters.Name())
+
+		if len(letters) != 1 {
+			t.Fatalf("got %d dead letters want 1", len(letters))
+		}
+
+		if letters[0].URL != server.URL || letters[0].Attempts != 3 || letters[0].Event.Player != "Pepper" {
+			t.Errorf("got dead letter %+v", letters[0])
+		}
+	})
+
+	t.Run("dead-letters events waiting to be retried when closed", func(t *testing.T) {
+		_, server := newWebhookReceiver(t, 10)
+		deadLetters, clean := createTempFile(t, "")
+		defer clean()
+
+		webhooks := newWebhooks(t, []string{server.URL}, WithWebhookRetries(5, time.Hour), WithDeadLetterFile(deadLetters))
+
+		webhooks.Send(WebhookEvent{Type: WebhookEventWin, Player: "Pepper"})
+		webhooks.Send(WebhookEvent{Type: WebhookEventWin, Player: "Floyd"})
+
+		closed := make(chan struct{})
+		go func() {
+			webhooks.Close()
+			close(closed)
+		}()
+
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Close waited to retry deliveries")
+		}
+
+		letters := readDeadLetters(t, deadLetters.Name())
+
+		if len(letters) != 2 || letters[0].Attempts != 1 || letters[1].Attempts != 1 {
+			t.Errorf("got dead letters %+v want both events after one attempt", letters)
+		}
+	})
+
+	t.Run("needs a secret to sign events with", func(t *testing.T) {
+		if _, err := NewWebhooks([]string{"http://127.0.0.1:0/hook"}, nil); err != ErrNoWebhookSecret {
+			t.Errorf("got %v want %v", err, ErrNoWebhookSecret)
+		}
+	})
+
+	t.Run("dead-letters events sent after close", func(t *testing.T) {
+		_, server := newWebhookReceiver(t, 0)
+		deadLetters, clean := createTempFile(t, "")
+		defer clean()
+
+		webhooks := newWebhooks(t, []string{server.URL}, WithDeadLetterFile(deadLetters))
+		webhooks.Close()
+		webhooks.Send(WebhookEvent{Type: WebhookEventWin, Player: "Pepper"})
+
+		if got := len(readDeadLetters(t, deadLetters.Name())); got != 1 {
+			t.Errorf("got %d dead letters want 1", got)
+		}
+	})
+}
+
+func TestServerWebhooks(t *testing.T) {
+	t.Run("sends wins and leader changes", func(t *testing.T) {
+		receiver, hook := newWebhookReceiver(t, 0)
+		store := &StubPlayerStore{scores: map[string]int{"Floyd": 1}}
+		webhooks := newWebhooks(t, []string{hook.URL})
+		server := NewPlayerServer(store, WithWebhooks(webhooks))
+
+		serve(server, newPostWinRequest("Pepper"))
+		serve(server, newPostWinRequest("Pepper"))
+		serve(server, newPostWinRequest("Pepper"))
+		webhooks.Close()
+
+		assertWebhookEvents(t, receiver.received(), []WebhookEvent{
+			{Type: WebhookEventWin, Player: "Pepper"},
+			{Type: WebhookEventWin, Player: "Pepper"},
+			{Type: WebhookEventLeaderChanged, Player: "Pepper", Previous: "Floyd"},
+			{Type: WebhookEventWin, Player: "Pepper"},
+		})
+	})
+}
+
+// waitFor waits a few seconds for done to report true.
+func waitFor(t testing.TB, done func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); !done(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("gave up waiting")
+		}
+	}
+}
+
+func assertWebhookEvents(t testing.TB, got, want []WebhookEvent) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d events %+v want %d", len(got), got, len(want))
+	}
+
+	for i := range want {
+		if got[i].Type != want[i].Type || got[i].Player != want[i].Player || got[i].Previous != want[i].Previous {
+			t.Errorf("event %d got %+v want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func readDeadLetters(t testing.TB, path string) []DeadLetter {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	assertNoError(t, err)
+
+	var letters []DeadLetter
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var letter DeadLetter
+		assertNoError(t, json.Unmarshal([]byte(line), &letter))
+		letters = append(letters, letter)
+	}
+
+	return letters
+}
@@ -1,26 +1,46 @@
 package main
 
+import (
+	"sort"
+	"sync"
+)
+
 func NewInMemoryPlayerStore() *InMemoryPlayerStore {
-	return &InMemoryPlayerStore{map[string]int{}}
+	return &InMemoryPlayerStore{store: map[string]int{}}
 }
 
 type InMemoryPlayerStore struct {
+	mu    sync.RWMutex
 	store map[string]int
 }
 
 func (i *InMemoryPlayerStore) GetPlayerScore(name string) int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	return i.store[name]
 }
 
 func (i *InMemoryPlayerStore) RecordWin(name string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	i.store[name]++
 }
 
 func (i *InMemoryPlayerStore) GetLeague() []Player {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
 	var league []Player
 
 	for name, wins := range i.store {
 		league = append(league, Player{Name: name, Wins: wins})
 	}
+
+	sort.Slice(league, func(a, b int) bool {
+		if league[a].Wins != league[b].Wins {
+			return league[a].Wins > league[b].Wins
+		}
+		return league[a].Name < league[b].Name
+	})
 	return league
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestInMemoryPlayerStore(t *testing.T) {
+	runPlayerStoreContract(t, NewInMemoryPlayerStore)
+}
+
+// runPlayerStoreContract is this chapter's copy of storetest.RunPlayerStoreContract from
+// command-line-and-project-structure, which this module is kept independent of. It has the
+// contract's tests for what this chapter's store can do, and should change along with it.
+func runPlayerStoreContract(t *testing.T, newStore func() *InMemoryPlayerStore) {
+	t.Helper()
+
+	t.Run("unknown players have no wins", func(t *testing.T) {
+		store := newStore()
+
+		assertScore(t, store, "Apollo", 0)
+	})
+
+	t.Run("records wins for new players", func(t *testing.T) {
+		store := newStore()
+
+		recordWins(store, "Pepper", 1)
+
+		assertScore(t, store, "Pepper", 1)
+		assertStoreLeague(t, store, []Player{{"Pepper", 1}})
+	})
+
+	t.Run("records wins for existing players", func(t *testing.T) {
+		store := newStore()
+
+		recordWins(store, "Chris", 3)
+		recordWins(store, "Cleo", 2)
+
+		assertScore(t, store, "Chris", 3)
+		assertScore(t, store, "Cleo", 2)
+	})
+
+	t.Run("league is ordered by wins", func(t *testing.T) {
+		store := newStore()
+
+		recordWins(store, "Cleo", 1)
+		recordWins(store, "Tiest", 3)
+		recordWins(store, "Chris", 2)
+
+		assertStoreLeague(t, store, []Player{
+			{"Tiest", 3},
+			{"Chris", 2},
+			{"Cleo", 1},
+		})
+	})
+
+	t.Run("league orders players with the same wins by name", func(t *testing.T) {
+		store := newStore()
+
+		for _, player := range []string{"Pepper", "Chris", "Tiest", "Cleo"} {
+			recordWins(store, player, 1)
+		}
+		recordWins(store, "Tiest", 1)
+
+		assertStoreLeague(t, store, []Player{
+			{"Tiest", 2},
+			{"Chris", 1},
+			{"Cleo", 1},
+			{"Pepper", 1},
+		})
+	})
+
+	t.Run("empty league", func(t *testing.T) {
+		store := newStore()
+
+		assertStoreLeague(t, store, []Player{})
+	})
+
+	t.Run("records concurrent wins", func(t *testing.T) {
+		store := newStore()
+		players := []string{"Chris", "Cleo", "Pepper"}
+		wantedWins := 20
+
+		var wg sync.WaitGroup
+
+		for _, player := range players {
+			for i := 0; i < wantedWins; i++ {
+				wg.Add(1)
+				go func(player string) {
+					defer wg.Done()
+					store.RecordWin(player)
+				}(player)
+			}
+		}
+
+		wg.Wait()
+
+		for _, player := range players {
+			assertScore(t, store, player, wantedWins)
+		}
+	})
+}
+
+func recordWins(store *InMemoryPlayerStore, player string, wins int) {
+	for i := 0; i < wins; i++ {
+		store.RecordWin(player)
+	}
+}
+
+func assertScore(t testing.TB, store *InMemoryPlayerStore, player string, want int) {
+	t.Helper()
+
+	if got := store.GetPlayerScore(player); got != want {
+		t.Errorf("got %d wins for %s want %d", got, player, want)
+	}
+}
+
+// assertStoreLeague checks the league is exactly the players wanted, most wins first and players
+// with the same number of wins by name.
+func assertStoreLeague(t testing.TB, store *InMemoryPlayerStore, want []Player) {
+	t.Helper()
+
+	if got := store.GetLeague(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got league %v want %v", got, want)
+	}
+}